@@ -1,21 +1,245 @@
 package gomlp
 
-import "math"
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Names of the activation functions registered by default
+const (
+	Sigmoid   = "sigmoid"
+	Tanh      = "tanh"
+	ReLU      = "relu"
+	LeakyReLU = "leaky_relu"
+	ELU       = "elu"
+	Softplus  = "softplus"
+	GELU      = "gelu"
+	Identity  = "identity"
+	Softmax   = "softmax"
+)
+
+var leakyReLUSlope = 0.01
+
+var eluAlpha = 1.0
+
+var activationRegistry = struct {
+	sync.RWMutex
+	functions map[string]ActivationFunction
+}{functions: make(map[string]ActivationFunction)}
 
 var sigmoid = ActivationFunction{
+	name: Sigmoid,
 	function: func(x float64) float64 {
 		return 1 / (1 + math.Exp(-1*x))
 	},
-	dfunction: func(y float64) float64 {
-		return y*(1-y)
+	dfunction: func(x, y float64) float64 {
+		return y * (1 - y)
 	},
 }
 
 var tanh = ActivationFunction{
+	name: Tanh,
+	function: func(x float64) float64 {
+		return math.Tanh(x)
+	},
+	dfunction: func(x, y float64) float64 {
+		return 1 - y*y
+	},
+}
+
+var relu = ActivationFunction{
+	name: ReLU,
+	function: func(x float64) float64 {
+		return math.Max(0, x)
+	},
+	dfunction: func(x, y float64) float64 {
+		if x > 0 {
+			return 1
+		}
+		return 0
+	},
+}
+
+var leakyRelu = ActivationFunction{
+	name: LeakyReLU,
+	function: func(x float64) float64 {
+		if x > 0 {
+			return x
+		}
+		return leakyReLUSlope * x
+	},
+	dfunction: func(x, y float64) float64 {
+		if x > 0 {
+			return 1
+		}
+		return leakyReLUSlope
+	},
+}
+
+var elu = ActivationFunction{
+	name: ELU,
+	function: func(x float64) float64 {
+		if x > 0 {
+			return x
+		}
+		return eluAlpha * (math.Exp(x) - 1)
+	},
+	dfunction: func(x, y float64) float64 {
+		if x > 0 {
+			return 1
+		}
+		return y + eluAlpha
+	},
+}
+
+var softplus = ActivationFunction{
+	name: Softplus,
+	function: func(x float64) float64 {
+		if x > 30 {
+			return x
+		}
+		return math.Log1p(math.Exp(x))
+	},
+	dfunction: func(x, y float64) float64 {
+		return 1 / (1 + math.Exp(-1*x))
+	},
+}
+
+var gelu = ActivationFunction{
+	name: GELU,
+	function: func(x float64) float64 {
+		return 0.5 * x * (1 + math.Erf(x/math.Sqrt2))
+	},
+	dfunction: func(x, y float64) float64 {
+		cdf := 0.5 * (1 + math.Erf(x/math.Sqrt2))
+		pdf := math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+		return cdf + x*pdf
+	},
+}
+
+var identity = ActivationFunction{
+	name: Identity,
 	function: func(x float64) float64 {
-		return math.Atanh(x)
+		return x
 	},
-	dfunction: func(y float64) float64 {
-		return 1-y*y
+	dfunction: func(x, y float64) float64 {
+		return 1
 	},
-}
\ No newline at end of file
+}
+
+var softmax = ActivationFunction{
+	name: Softmax,
+	mfunction: func(z *Matrix) *Matrix {
+		m := z.Copy()
+		for j := 0; j < m.cols; j++ {
+			max := math.Inf(-1)
+			for i := 0; i < m.rows; i++ {
+				max = math.Max(max, m.data[i][j])
+			}
+			var sum float64
+			for i := 0; i < m.rows; i++ {
+				m.data[i][j] = math.Exp(m.data[i][j] - max)
+				sum = sum + m.data[i][j]
+			}
+			for i := 0; i < m.rows; i++ {
+				m.data[i][j] = m.data[i][j] / sum
+			}
+		}
+		return m
+	},
+	mdfunction: func(y, gradient *Matrix) *Matrix {
+		m, _ := NewMatrix(y.rows, y.cols)
+		for j := 0; j < y.cols; j++ {
+			var dot float64
+			for i := 0; i < y.rows; i++ {
+				dot = dot + y.data[i][j]*gradient.data[i][j]
+			}
+			for i := 0; i < y.rows; i++ {
+				m.data[i][j] = y.data[i][j] * (gradient.data[i][j] - dot)
+			}
+		}
+		return m
+	},
+}
+
+func init() {
+	for _, activationFunc := range []ActivationFunction{sigmoid, tanh, relu, leakyRelu, elu, softplus, gelu, identity, softmax} {
+		activationRegistry.functions[activationFunc.name] = activationFunc
+	}
+	registerApproximations()
+}
+
+// RegisterActivation registers an activation function and its derivative under a name
+// The derivative receives both the input x and the output y of the activation function
+func RegisterActivation(name string, function func(float64) float64, derivative func(x, y float64) float64) error {
+	if name == "" || function == nil || derivative == nil {
+		return ErrActivationFunction
+	}
+
+	activationRegistry.Lock()
+	defer activationRegistry.Unlock()
+	if _, ok := activationRegistry.functions[name]; ok {
+		return ErrActivationExists
+	}
+	activationRegistry.functions[name] = ActivationFunction{
+		name:      name,
+		function:  function,
+		dfunction: derivative,
+	}
+	return nil
+}
+
+// GetActivation returns the activation function registered under a name
+func GetActivation(name string) (ActivationFunction, error) {
+	activationRegistry.RLock()
+	defer activationRegistry.RUnlock()
+	activationFunc, ok := activationRegistry.functions[name]
+	if !ok {
+		return ActivationFunction{}, ErrUnknownActivation
+	}
+	return activationFunc, nil
+}
+
+// ActivationNames returns the sorted names of all the registered activation functions
+func ActivationNames() []string {
+	activationRegistry.RLock()
+	defer activationRegistry.RUnlock()
+	names := make([]string, 0, len(activationRegistry.functions))
+	for name := range activationRegistry.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the name the activation function is registered under
+func (a ActivationFunction) Name() string {
+	return a.name
+}
+
+// apply returns a new Matrix with the activation function applied to the given Matrix
+func (a ActivationFunction) apply(z *Matrix) *Matrix {
+	if a.mfunction != nil {
+		return a.mfunction(z)
+	}
+	return Map(z, a.function)
+}
+
+// backward returns the gradient with respect to the inputs z given the gradient with respect to the outputs y
+func (a ActivationFunction) backward(z, y, gradient *Matrix) (*Matrix, error) {
+	if (z.rows != gradient.rows || z.cols != gradient.cols) || (y.rows != gradient.rows || y.cols != gradient.cols) {
+		return &Matrix{}, ErrRowColumnDimension
+	}
+	if a.mdfunction != nil {
+		return a.mdfunction(y, gradient), nil
+	}
+
+	m, _ := NewMatrix(z.rows, z.cols)
+	for i := 0; i < z.rows; i++ {
+		for j := 0; j < z.cols; j++ {
+			m.data[i][j] = a.dfunction(z.data[i][j], y.data[i][j]) * gradient.data[i][j]
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,81 @@
+package gomlp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestActivationRegistry(t *testing.T) {
+	square := func(x float64) float64 { return x * x }
+	derivative := func(x, y float64) float64 { return 2 * x }
+	if err := RegisterActivation("test_square", square, derivative); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterActivation("test_square", square, derivative); err != ErrActivationExists {
+		t.Errorf("got error %v, want %v", err, ErrActivationExists)
+	}
+	if err := RegisterActivation("", square, derivative); err != ErrActivationFunction {
+		t.Errorf("got error %v, want %v", err, ErrActivationFunction)
+	}
+	if err := RegisterActivation("test_missing", square, nil); err != ErrActivationFunction {
+		t.Errorf("got error %v, want %v", err, ErrActivationFunction)
+	}
+
+	activation, err := GetActivation("test_square")
+	if err != nil || activation.Name() != "test_square" || activation.function(3) != 9 {
+		t.Errorf("got %v with error %v", activation.Name(), err)
+	}
+	found := false
+	for _, name := range ActivationNames() {
+		found = found || name == "test_square"
+	}
+	if !found {
+		t.Errorf("got names %v without the registered function", ActivationNames())
+	}
+	if _, err = GetActivation("test_unknown"); err != ErrUnknownActivation {
+		t.Errorf("got error %v, want %v", err, ErrUnknownActivation)
+	}
+
+	nn, err := newNetwork([]int{2, 3, 1}, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = nn.SetActivations([]string{"test_square", Sigmoid}); err != nil {
+		t.Fatal(err)
+	}
+	if err = nn.SetActivations([]string{Sigmoid}); err != ErrActivationCount {
+		t.Errorf("got error %v, want %v", err, ErrActivationCount)
+	}
+	if err = nn.SetActivations([]string{Sigmoid, "test_unknown"}); err != ErrUnknownActivation {
+		t.Errorf("got error %v, want %v", err, ErrUnknownActivation)
+	}
+}
+
+func TestActivationGradients(t *testing.T) {
+	matchesNumericalGradients(t, []string{ELU, Softplus, Tanh}, MeanSquaredError, [][]float64{{0.2, 0.9}, {0.7, 0.1}})
+	matchesNumericalGradients(t, []string{GELU, LeakyReLU, Sigmoid}, MeanSquaredError, [][]float64{{0.2, 0.9}, {0.7, 0.1}})
+}
+
+func TestSoftmaxIsStable(t *testing.T) {
+	softmax, err := GetActivation(Softmax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, _ := ConvertFromArray2DToMatrix([][]float64{{1000, -1000}, {1001, -1000}, {999, -1000}})
+	y := softmax.apply(z)
+	for c := 0; c < y.cols; c++ {
+		var sum float64
+		for r := 0; r < y.rows; r++ {
+			if math.IsNaN(y.data[r][c]) {
+				t.Fatalf("got NaN for column %d", c)
+			}
+			sum = sum + y.data[r][c]
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("column %d: got sum %v, want 1", c, sum)
+		}
+	}
+	if y.data[1][0] <= y.data[0][0] || y.data[0][0] <= y.data[2][0] {
+		t.Errorf("got %v", y.data)
+	}
+}
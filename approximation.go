@@ -0,0 +1,246 @@
+package gomlp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Names of the hardware friendly approximations registered by default
+const (
+	PLANSigmoid   = "plan_sigmoid"
+	PiecewiseTanh = "pwl_tanh"
+	LUTSigmoid    = "lut_sigmoid"
+	LUTTanh       = "lut_tanh"
+)
+
+var approximationSamples = 10001
+
+var approximationRegistry = struct {
+	sync.RWMutex
+	approximations map[string]approximation
+}{approximations: make(map[string]approximation)}
+
+// approximation is the Data Structure to hold the exact function, the shape and the input range of an approximation
+type approximation struct {
+	reference string
+	shape     string
+	size      int
+	min       float64
+	max       float64
+}
+
+// Shapes of approximations
+const (
+	planShape        = "plan"
+	piecewiseShape   = "piecewise"
+	lookupTableShape = "lookup_table"
+)
+
+// plan returns the PLAN piecewise linear approximation of the sigmoid and the slope of its segment
+func plan(x float64) (float64, float64) {
+	abs := math.Abs(x)
+	var y, slope float64
+	switch {
+	case abs >= 5:
+		y, slope = 1, 0
+	case abs >= 2.375:
+		y, slope = 0.03125*abs+0.84375, 0.03125
+	case abs >= 1:
+		y, slope = 0.125*abs+0.625, 0.125
+	default:
+		y, slope = 0.25*abs+0.5, 0.25
+	}
+	if x < 0 {
+		return 1 - y, slope
+	}
+	return y, slope
+}
+
+var planSigmoid = ActivationFunction{
+	name: PLANSigmoid,
+	function: func(x float64) float64 {
+		y, _ := plan(x)
+		return y
+	},
+	dfunction: func(x, y float64) float64 {
+		_, slope := plan(x)
+		return slope
+	},
+}
+
+// registerApproximations registers the default approximations once the exact functions are registered
+func registerApproximations() {
+	activationRegistry.functions[planSigmoid.name] = planSigmoid
+	approximationRegistry.approximations[planSigmoid.name] = approximation{Sigmoid, planShape, 4, -8, 8}
+
+	if err := RegisterPiecewiseTanh(PiecewiseTanh, 8, 4); err != nil {
+		panic(err)
+	}
+	if err := RegisterLookupTable(LUTSigmoid, Sigmoid, 256, -8, 8); err != nil {
+		panic(err)
+	}
+	if err := RegisterLookupTable(LUTTanh, Tanh, 256, -4, 4); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterPiecewiseTanh registers a tanh approximated by equal segments between -limit and limit
+// The segments interpolate tanh at their ends and the approximation stays constant outside the limits
+func RegisterPiecewiseTanh(name string, segments int, limit float64) error {
+	if segments < 1 || limit <= 0 {
+		return ErrApproximation
+	}
+
+	xs := make([]float64, segments+1)
+	ys := make([]float64, segments+1)
+	for i := range xs {
+		xs[i] = -1*limit + 2*limit*float64(i)/float64(segments)
+		ys[i] = math.Tanh(xs[i])
+	}
+	segment := func(x float64) (float64, float64) {
+		if x <= xs[0] {
+			return ys[0], 0
+		}
+		if x >= xs[segments] {
+			return ys[segments], 0
+		}
+		i := sort.SearchFloat64s(xs, x)
+		if i > 0 {
+			i = i - 1
+		}
+		slope := (ys[i+1] - ys[i]) / (xs[i+1] - xs[i])
+		return ys[i] + slope*(x-xs[i]), slope
+	}
+
+	err := RegisterActivation(name, func(x float64) float64 {
+		y, _ := segment(x)
+		return y
+	}, func(x, y float64) float64 {
+		_, slope := segment(x)
+		return slope
+	})
+	if err != nil {
+		return err
+	}
+	registerApproximation(name, approximation{Tanh, piecewiseShape, segments, -1 * limit, limit})
+	return nil
+}
+
+// RegisterLookupTable registers a lookup table approximation of a registered activation function
+// The table holds size entries sampled at the centres of equal steps between min and max and inputs outside
+// the range read the first or the last entry
+// The derivative used in training is the derivative of the exact function
+func RegisterLookupTable(name, reference string, size int, min, max float64) error {
+	if size < 1 || min >= max {
+		return ErrApproximation
+	}
+	exact, err := GetActivation(reference)
+	if err != nil {
+		return err
+	}
+	if exact.function == nil {
+		return ErrApproximation
+	}
+
+	step := (max - min) / float64(size)
+	table := make([]float64, size)
+	for i := range table {
+		table[i] = exact.function(min + (float64(i)+0.5)*step)
+	}
+
+	err = RegisterActivation(name, func(x float64) float64 {
+		address := int(math.Floor((x - min) / step))
+		if address < 0 || math.IsNaN(x) {
+			address = 0
+		}
+		if address >= size {
+			address = size - 1
+		}
+		return table[address]
+	}, func(x, y float64) float64 {
+		return exact.dfunction(x, exact.function(x))
+	})
+	if err != nil {
+		return err
+	}
+	registerApproximation(name, approximation{reference, lookupTableShape, size, min, max})
+	return nil
+}
+
+// registerApproximation records the exact function, the shape and the input range of an approximation
+func registerApproximation(name string, a approximation) {
+	approximationRegistry.Lock()
+	defer approximationRegistry.Unlock()
+	approximationRegistry.approximations[name] = a
+}
+
+// getApproximation returns the approximation registered under a name
+func getApproximation(name string) (approximation, bool) {
+	approximationRegistry.RLock()
+	defer approximationRegistry.RUnlock()
+	a, ok := approximationRegistry.approximations[name]
+	return a, ok
+}
+
+// CompareActivations returns the maximum and mean absolute error of an activation function against a reference
+// sampled evenly between min and max
+func CompareActivations(name, reference string, min, max float64) (ApproximationReport, error) {
+	if min > max {
+		return ApproximationReport{}, ErrApproximation
+	}
+	approximate, err := GetActivation(name)
+	if err != nil {
+		return ApproximationReport{}, err
+	}
+	exact, err := GetActivation(reference)
+	if err != nil {
+		return ApproximationReport{}, err
+	}
+	if approximate.function == nil || exact.function == nil {
+		return ApproximationReport{}, ErrApproximation
+	}
+
+	report := ApproximationReport{Name: name, Reference: reference, Min: min, Max: max, MaxErrorAt: min}
+	for i := 0; i < approximationSamples; i++ {
+		x := min + (max-min)*float64(i)/float64(approximationSamples-1)
+		e := math.Abs(approximate.function(x) - exact.function(x))
+		if e > report.MaxError {
+			report.MaxError = e
+			report.MaxErrorAt = x
+		}
+		report.MeanError = report.MeanError + e
+	}
+	report.MeanError = report.MeanError / float64(approximationSamples)
+	return report, nil
+}
+
+// ApproximationReports returns the error of every registered approximation against its exact function
+// over the input range of the approximation
+func ApproximationReports() []ApproximationReport {
+	approximationRegistry.RLock()
+	approximations := make(map[string]approximation, len(approximationRegistry.approximations))
+	names := make([]string, 0, len(approximationRegistry.approximations))
+	for name, a := range approximationRegistry.approximations {
+		approximations[name] = a
+		names = append(names, name)
+	}
+	approximationRegistry.RUnlock()
+	sort.Strings(names)
+
+	reports := make([]ApproximationReport, 0, len(names))
+	for _, name := range names {
+		a := approximations[name]
+		report, err := CompareActivations(name, a.reference, a.min, a.max)
+		if err == nil {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// String returns the report as a line of text
+func (r ApproximationReport) String() string {
+	return fmt.Sprintf("%s vs %s on [%g, %g]: max error %.6g at %.6g, mean error %.6g", r.Name, r.Reference, r.Min, r.Max, r.MaxError, r.MaxErrorAt, r.MeanError)
+}
@@ -0,0 +1,67 @@
+package gomlp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDefaultApproximationErrors(t *testing.T) {
+	bounds := map[string]float64{
+		PLANSigmoid:   0.02,
+		PiecewiseTanh: 0.09,
+		LUTSigmoid:    0.01,
+		LUTTanh:       0.02,
+	}
+	reports := ApproximationReports()
+	found := 0
+	for _, report := range reports {
+		bound, ok := bounds[report.Name]
+		if !ok {
+			continue
+		}
+		found = found + 1
+		if report.MaxError > bound || report.MeanError > report.MaxError {
+			t.Errorf("%s", report)
+		}
+	}
+	if found != len(bounds) {
+		t.Errorf("got %d of the %d default approximations in %v", found, len(bounds), reports)
+	}
+}
+
+func TestLookupTableHoldsItsEnds(t *testing.T) {
+	if err := RegisterLookupTable("test_lut_tanh", Tanh, 4, -2, 2); err != nil {
+		t.Fatal(err)
+	}
+	lut, _ := GetActivation("test_lut_tanh")
+	cases := map[float64]float64{
+		-100:       math.Tanh(-1.5),
+		-2:         math.Tanh(-1.5),
+		-0.5:       math.Tanh(-0.5),
+		0:          math.Tanh(0.5),
+		1.99:       math.Tanh(1.5),
+		100:        math.Tanh(1.5),
+		math.NaN(): math.Tanh(-1.5),
+	}
+	for x, want := range cases {
+		if got := lut.function(x); got != want {
+			t.Errorf("%v: got %v, want %v", x, got, want)
+		}
+	}
+	if err := RegisterLookupTable("test_lut_empty", Tanh, 0, -2, 2); err != ErrApproximation {
+		t.Errorf("got error %v, want %v", err, ErrApproximation)
+	}
+	if err := RegisterPiecewiseTanh("test_pwl_empty", 4, 0); err != ErrApproximation {
+		t.Errorf("got error %v, want %v", err, ErrApproximation)
+	}
+}
+
+func TestPLANSigmoidIsSymmetric(t *testing.T) {
+	for x := -6.0; x <= 6; x = x + 0.125 {
+		y, slope := plan(x)
+		mirrored, mirroredSlope := plan(-x)
+		if math.Abs(y+mirrored-1) > 1e-12 || slope != mirroredSlope {
+			t.Errorf("%v: got %v and %v for %v", x, y, mirrored, -x)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package gomlp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// BinaryPrecision is the width of the floating point values written by SaveBinary
+type BinaryPrecision uint8
+
+// Widths of the floating point values of a binary model file
+const (
+	Float32Precision BinaryPrecision = 4
+	Float64Precision BinaryPrecision = 8
+)
+
+// BinaryFormatVersion is the version of the binary model file format written by SaveBinary
+const BinaryFormatVersion = 1
+
+var binaryMagic = [4]byte{'G', 'M', 'L', 'B'}
+
+// kinds of models in the header of a binary model file
+var binaryKinds = []string{"", ClassifierModel, RegressorModel}
+
+// writeBinary writes the layer sizes, activation names, classes, weights and biases of the network in little endian
+// The file ends with the SHA-256 checksum of everything before it
+func (nn *network) writeBinary(w io.Writer, kind string, classes []float64, precision BinaryPrecision) error {
+	if precision != Float32Precision && precision != Float64Precision {
+		return ErrBinaryPrecision
+	}
+
+	var buffer bytes.Buffer
+	order := binary.LittleEndian
+	header := []interface{}{
+		binaryMagic,
+		uint16(BinaryFormatVersion),
+		uint8(precision),
+		uint8(FindInStrings(binaryKinds, kind)),
+		uint32(len(nn.layers)),
+	}
+	for _, field := range header {
+		err := binary.Write(&buffer, order, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, layer := range nn.layers {
+		name := layer.activationFunc.name
+		if len(name) > math.MaxUint16 {
+			return ErrActivationFunction
+		}
+		for _, field := range []interface{}{uint32(layer.inputNodes), uint32(layer.outputNodes), uint16(len(name)), []byte(name)} {
+			err := binary.Write(&buffer, order, field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := binary.Write(&buffer, order, uint32(len(classes)))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(&buffer, order, classes)
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range nn.layers {
+		values := append(layer.weights.ConvertFromMatrixToArray1D(), layer.bias.ConvertFromMatrixToArray1D()...)
+		if precision == Float32Precision {
+			narrow := make([]float32, len(values))
+			for i, value := range values {
+				narrow[i] = float32(value)
+			}
+			err = binary.Write(&buffer, order, narrow)
+		} else {
+			err = binary.Write(&buffer, order, values)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	checksum := sha256.Sum256(buffer.Bytes())
+	buffer.Write(checksum[:])
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+// readBinary reads a binary model file after verifying its checksum
+// It returns the network, the kind of model and the classes
+func readBinary(r io.Reader) (*network, string, []float64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	if len(data) < sha256.Size {
+		return &network{}, "", nil, ErrModelFormat
+	}
+
+	body := data[:len(data)-sha256.Size]
+	checksum := sha256.Sum256(body)
+	if !bytes.Equal(checksum[:], data[len(body):]) {
+		return &network{}, "", nil, ErrChecksum
+	}
+
+	reader := bytes.NewReader(body)
+	order := binary.LittleEndian
+	var magic [4]byte
+	var version uint16
+	var precision, kind uint8
+	var layerCount uint32
+	for _, field := range []interface{}{&magic, &version, &precision, &kind, &layerCount} {
+		err = binary.Read(reader, order, field)
+		if err != nil {
+			return &network{}, "", nil, ErrModelFormat
+		}
+	}
+	if magic != binaryMagic {
+		return &network{}, "", nil, ErrModelFormat
+	}
+	if version != BinaryFormatVersion {
+		return &network{}, "", nil, ErrModelVersion
+	}
+	if BinaryPrecision(precision) != Float32Precision && BinaryPrecision(precision) != Float64Precision {
+		return &network{}, "", nil, ErrBinaryPrecision
+	}
+	if int(kind) >= len(binaryKinds) || uint64(layerCount)*8 > uint64(reader.Len()) {
+		return &network{}, "", nil, ErrModelFormat
+	}
+
+	// the shapes of all layers are read and checked against the remaining bytes before any layer is allocated
+	shapes := make([][2]uint32, layerCount)
+	activations := make([]string, layerCount)
+	var declared uint64
+	for i := range shapes {
+		var inputs, outputs uint32
+		var nameLen uint16
+		for _, field := range []interface{}{&inputs, &outputs, &nameLen} {
+			err = binary.Read(reader, order, field)
+			if err != nil {
+				return &network{}, "", nil, ErrModelFormat
+			}
+		}
+		name := make([]byte, nameLen)
+		_, err = io.ReadFull(reader, name)
+		if err != nil {
+			return &network{}, "", nil, ErrModelFormat
+		}
+		activations[i] = string(name)
+
+		values := uint64(inputs)*uint64(outputs) + uint64(outputs)
+		if values > uint64(reader.Len()) {
+			return &network{}, "", nil, ErrModelFormat
+		}
+		declared = declared + values*uint64(precision)
+		if declared > uint64(reader.Len()) {
+			return &network{}, "", nil, ErrModelFormat
+		}
+		shapes[i] = [2]uint32{inputs, outputs}
+	}
+
+	layers := make([]*Layer, layerCount)
+	for i, shape := range shapes {
+		layers[i], err = NewLayer(int(shape[0]), int(shape[1]))
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		layers[i].weightsInit = Initializer{}
+		layers[i].biasInit = Initializer{}
+	}
+
+	var classCount uint32
+	err = binary.Read(reader, order, &classCount)
+	if err != nil || uint64(classCount)*8 > uint64(reader.Len()) {
+		return &network{}, "", nil, ErrModelFormat
+	}
+	var classes []float64
+	if classCount > 0 {
+		classes = make([]float64, classCount)
+		err = binary.Read(reader, order, classes)
+		if err != nil {
+			return &network{}, "", nil, ErrModelFormat
+		}
+	}
+
+	for _, layer := range layers {
+		for _, m := range []*Matrix{layer.weights, layer.bias} {
+			values := make([]float64, m.rows*m.cols)
+			if BinaryPrecision(precision) == Float32Precision {
+				narrow := make([]float32, len(values))
+				err = binary.Read(reader, order, narrow)
+				for i, value := range narrow {
+					values[i] = float64(value)
+				}
+			} else {
+				err = binary.Read(reader, order, values)
+			}
+			if err != nil {
+				return &network{}, "", nil, ErrModelFormat
+			}
+			for i := 0; i < m.rows; i++ {
+				copy(m.data[i], values[i*m.cols:(i+1)*m.cols])
+			}
+		}
+	}
+	if reader.Len() != 0 {
+		return &network{}, "", nil, ErrModelFormat
+	}
+
+	nn, err := newNetworkFromLayers(layers)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	err = nn.SetActivations(activations)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	return nn, binaryKinds[kind], classes, nil
+}
+
+// SaveBinary writes the weights, biases, activations and classes as a compact binary model file with a checksum
+// Training state such as the optimizer is not included
+func (mlp *Classifier) SaveBinary(w io.Writer, precision BinaryPrecision) error {
+	return mlp.writeBinary(w, ClassifierModel, mlp.Classes, precision)
+}
+
+// LoadClassifierBinary return a new pointer to the Classifier Class from a binary model file
+// Files whose checksum does not match their contents are rejected
+func LoadClassifierBinary(r io.Reader) (*Classifier, error) {
+	nn, kind, classes, err := readBinary(r)
+	if err != nil {
+		return &Classifier{}, err
+	}
+	if kind != ClassifierModel {
+		return &Classifier{}, ErrModelKind
+	}
+
+	return &Classifier{
+		nn,
+		classes,
+	}, nil
+}
+
+// SaveBinary writes the weights, biases and activations as a compact binary model file with a checksum
+// Training state such as the optimizer is not included
+func (r *Regressor) SaveBinary(w io.Writer, precision BinaryPrecision) error {
+	return r.writeBinary(w, RegressorModel, nil, precision)
+}
+
+// LoadRegressorBinary return a new pointer to the Regressor Class from a binary model file
+// Files whose checksum does not match their contents are rejected
+func LoadRegressorBinary(r io.Reader) (*Regressor, error) {
+	nn, kind, _, err := readBinary(r)
+	if err != nil {
+		return &Regressor{}, err
+	}
+	if kind != RegressorModel {
+		return &Regressor{}, ErrModelKind
+	}
+
+	return &Regressor{
+		nn,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package gomlp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 5, 3}, 3)
+	for _, precision := range []BinaryPrecision{Float64Precision, Float32Precision} {
+		var buffer bytes.Buffer
+		if err := mlp.SaveBinary(&buffer, precision); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadClassifierBinary(&buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := mlp.network
+		if precision == Float32Precision {
+			want = narrowed(mlp.network)
+		}
+		sameNetworks(t, loaded.network, want)
+		if !reflect.DeepEqual(loaded.Classes, mlp.Classes) {
+			t.Errorf("got classes %v, want %v", loaded.Classes, mlp.Classes)
+		}
+		if precision == Float64Precision {
+			for _, row := range data[:20] {
+				want, _ := mlp.PredictProbabilities(row)
+				got, err := loaded.PredictProbabilities(row)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("got outputs %v, want %v", got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestBinaryRejectsCorruptFiles(t *testing.T) {
+	mlp, _, _ := testClassifier(t, []int{2, 4, 3}, 1)
+	var buffer bytes.Buffer
+	if err := mlp.SaveBinary(&buffer, Float64Precision); err != nil {
+		t.Fatal(err)
+	}
+	saved := buffer.Bytes()
+
+	for _, position := range []int{0, 20, len(saved) / 2, len(saved) - sha256.Size - 1, len(saved) - 1} {
+		corrupt := append([]byte(nil), saved...)
+		corrupt[position] = corrupt[position] ^ 0x10
+		if _, err := LoadClassifierBinary(bytes.NewReader(corrupt)); err != ErrChecksum {
+			t.Errorf("byte %d: got error %v, want %v", position, err, ErrChecksum)
+		}
+	}
+	if _, err := LoadClassifierBinary(bytes.NewReader(saved[:10])); err != ErrModelFormat {
+		t.Errorf("got error %v, want %v", err, ErrModelFormat)
+	}
+	if _, err := LoadRegressorBinary(bytes.NewReader(saved)); err != ErrModelKind {
+		t.Errorf("got error %v, want %v", err, ErrModelKind)
+	}
+}
+
+// narrowed returns a copy of a network with every weight and bias rounded to float32
+func narrowed(nn *network) *network {
+	narrow := *nn
+	narrow.layers = make([]*Layer, len(nn.layers))
+	for i, layer := range nn.layers {
+		copied := *layer
+		copied.weights = Map(layer.weights, func(x float64) float64 { return float64(float32(x)) })
+		copied.bias = Map(layer.bias, func(x float64) float64 { return float64(float32(x)) })
+		narrow.layers[i] = &copied
+	}
+	return &narrow
+}
+
+// craftedBinary returns a binary model file with a valid checksum declaring layers of the given shapes and no values
+func craftedBinary(t *testing.T, layerCount uint32, shapes [][2]uint32) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	fields := []interface{}{binaryMagic, uint16(BinaryFormatVersion), uint8(Float64Precision), uint8(1), layerCount}
+	for _, shape := range shapes {
+		fields = append(fields, shape[0], shape[1], uint16(len(Sigmoid)), []byte(Sigmoid))
+	}
+	fields = append(fields, uint32(0))
+	for _, field := range fields {
+		if err := binary.Write(&buffer, binary.LittleEndian, field); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checksum := sha256.Sum256(buffer.Bytes())
+	return append(buffer.Bytes(), checksum[:]...)
+}
+
+func TestBinaryRejectsOversizedDeclarations(t *testing.T) {
+	small := make([][2]uint32, 200)
+	for i := range small {
+		small[i] = [2]uint32{1, 1}
+	}
+	cases := map[string][]byte{
+		"layer count":     craftedBinary(t, 1<<31, [][2]uint32{{2, 2}}),
+		"layer size":      craftedBinary(t, 1, [][2]uint32{{1 << 31, 1 << 31}}),
+		"total of layers": craftedBinary(t, 200, small),
+	}
+	for name, data := range cases {
+		if _, err := LoadClassifierBinary(bytes.NewReader(data)); err != ErrModelFormat {
+			t.Errorf("%s: got error %v, want %v", name, err, ErrModelFormat)
+		}
+	}
+}
@@ -0,0 +1,573 @@
+package gomlp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var cIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cModel is the Data Structure to hold a model being written as C
+// Float models keep their layers and fixed point models their raw weights
+type cModel struct {
+	name      string
+	precision BinaryPrecision
+	layers    []*Layer
+	fixed     *fixedPointNetwork
+	classes   []float64
+}
+
+// WriteC writes the classifier as a C99 header and source file named after a C identifier into a directory
+// The forward pass uses float or double arithmetic as chosen by the precision and no heap allocation
+// Classifiers trained with quantization are written through NewFixedPointClassifier instead
+func (mlp *Classifier) WriteC(path, name string, precision BinaryPrecision) error {
+	if precision != Float32Precision && precision != Float64Precision {
+		return ErrBinaryPrecision
+	}
+	if mlp.quantization != nil {
+		return ErrCodeGeneration
+	}
+	return writeCModel(path, &cModel{name, precision, mlp.layers, nil, mlp.Classes})
+}
+
+// WriteC writes the fixed point classifier as a C99 header and source file named after a C identifier into a
+// directory
+// The forward pass repeats the integer arithmetic of Forward with 64 bit integers and evaluates the activation
+// functions in double before quantizing them
+func (fc *FixedPointClassifier) WriteC(path, name string) error {
+	return writeCModel(path, &cModel{name, Float64Precision, nil, fc.fixedPointNetwork, fc.Classes})
+}
+
+// WriteCTestVectors writes a C test program checking the code written by WriteC against Predict
+// and PredictProbabilities on a dataset
+func (mlp *Classifier) WriteCTestVectors(path, name string, precision BinaryPrecision, data [][]float64) error {
+	if mlp.quantization != nil {
+		return ErrCodeGeneration
+	}
+	c := &cModel{name, precision, mlp.layers, nil, mlp.Classes}
+	err := c.checkLayers()
+	if err != nil {
+		return err
+	}
+	tolerance := 1e-9
+	if precision == Float32Precision {
+		tolerance = 1e-4
+	}
+	return writeCTest(path, name, mlp.layers[0].inputNodes, tolerance, data, mlp.PredictProbabilities, mlp.Predict)
+}
+
+// WriteCTestVectors writes a C test program checking the code written by WriteC against Predict
+// and Outputs on a dataset
+// Outputs may differ by one step of the activation format where the C library rounds an activation function
+// differently
+func (fc *FixedPointClassifier) WriteCTestVectors(path, name string, data [][]float64) error {
+	c := &cModel{name, Float64Precision, nil, fc.fixedPointNetwork, fc.Classes}
+	err := c.checkLayers()
+	if err != nil {
+		return err
+	}
+	tolerance := fc.config.activations.ToFloat(1)
+	return writeCTest(path, name, len(fc.layers[0].weights[0]), tolerance, data, fc.Outputs, fc.Predict)
+}
+
+// cFloat returns a floating point literal of the precision of the model
+func (c *cModel) cFloat(x float64) (string, error) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return "", ErrCodeGeneration
+	}
+	if c.precision == Float32Precision {
+		s := strconv.FormatFloat(x, 'g', -1, 32)
+		if !strings.ContainsAny(s, ".e") {
+			s = s + ".0"
+		}
+		return s + "f", nil
+	}
+	s := strconv.FormatFloat(x, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s = s + ".0"
+	}
+	return s, nil
+}
+
+// cDouble returns a double literal
+func cDouble(x float64) string {
+	s := strconv.FormatFloat(x, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s = s + ".0"
+	}
+	return s
+}
+
+// cInteger returns the C integer type holding words of a width
+func cInteger(width int) string {
+	if width <= 32 {
+		return "int32_t"
+	}
+	return "int64_t"
+}
+
+// dimensions returns the inputs, outputs and activation of every layer
+func (c *cModel) dimensions() ([]int, []int, []ActivationFunction) {
+	var inputs, outputs []int
+	var activations []ActivationFunction
+	if c.fixed != nil {
+		for _, layer := range c.fixed.layers {
+			width := 0
+			if len(layer.weights) > 0 {
+				width = len(layer.weights[0])
+			}
+			inputs = append(inputs, width)
+			outputs = append(outputs, len(layer.weights))
+			activations = append(activations, layer.activationFunc)
+		}
+		return inputs, outputs, activations
+	}
+	for _, layer := range c.layers {
+		inputs = append(inputs, layer.inputNodes)
+		outputs = append(outputs, layer.outputNodes)
+		activations = append(activations, layer.activationFunc)
+	}
+	return inputs, outputs, activations
+}
+
+// checkLayers returns an error if the model has no layers or a layer without inputs or outputs
+func (c *cModel) checkLayers() error {
+	inputs, outputs, _ := c.dimensions()
+	if len(inputs) == 0 {
+		return ErrEmptyLayer
+	}
+	for i := range inputs {
+		if inputs[i] == 0 || outputs[i] == 0 {
+			return ErrEmptyLayer
+		}
+	}
+	return nil
+}
+
+// cActivation returns the body of a C function computing an activation function of a double x
+func cActivation(a ActivationFunction) (string, error) {
+	switch a.name {
+	case Sigmoid:
+		return "return 1.0 / (1.0 + exp(-x));", nil
+	case Tanh:
+		return "return tanh(x);", nil
+	case ReLU:
+		return "return x > 0.0 ? x : 0.0;", nil
+	case LeakyReLU:
+		return fmt.Sprintf("return x > 0.0 ? x : %s * x;", cDouble(leakyReLUSlope)), nil
+	case ELU:
+		return fmt.Sprintf("return x > 0.0 ? x : %s * (exp(x) - 1.0);", cDouble(eluAlpha)), nil
+	case Softplus:
+		return "return x > 30.0 ? x : log1p(exp(x));", nil
+	case GELU:
+		return "return 0.5 * x * (1.0 + erf(x / 1.4142135623730951));", nil
+	case Identity:
+		return "return x;", nil
+	}
+
+	approx, ok := getApproximation(a.name)
+	if !ok {
+		return "", ErrCodeGeneration
+	}
+	switch approx.shape {
+	case planShape:
+		return `double a = fabs(x);
+	double y;
+	if (a >= 5.0) {
+		y = 1.0;
+	} else if (a >= 2.375) {
+		y = 0.03125 * a + 0.84375;
+	} else if (a >= 1.0) {
+		y = 0.125 * a + 0.625;
+	} else {
+		y = 0.25 * a + 0.5;
+	}
+	return x < 0.0 ? 1.0 - y : y;`, nil
+	case piecewiseShape:
+		xs := make([]string, approx.size+1)
+		ys := make([]string, approx.size+1)
+		for i := range xs {
+			x := approx.min + (approx.max-approx.min)*float64(i)/float64(approx.size)
+			xs[i] = cDouble(x)
+			ys[i] = cDouble(a.function(x))
+		}
+		return fmt.Sprintf(`static const double xs[%d] = {%s};
+	static const double ys[%d] = {%s};
+	int i;
+	if (x <= xs[0]) {
+		return ys[0];
+	}
+	if (x >= xs[%d]) {
+		return ys[%d];
+	}
+	for (i = 0; x > xs[i + 1]; i++) {
+	}
+	return ys[i] + (ys[i + 1] - ys[i]) / (xs[i + 1] - xs[i]) * (x - xs[i]);`, len(xs), strings.Join(xs, ", "), len(ys), strings.Join(ys, ", "), approx.size, approx.size), nil
+	case lookupTableShape:
+		step := (approx.max - approx.min) / float64(approx.size)
+		table := make([]string, approx.size)
+		for i := range table {
+			table[i] = cDouble(a.function(approx.min + (float64(i)+0.5)*step))
+		}
+		return fmt.Sprintf(`static const double table[%d] = {%s};
+	double address = floor((x - %s) / %s);
+	if (!(address >= 0.0)) {
+		return table[0];
+	}
+	if (address >= %d.0) {
+		return table[%d];
+	}
+	return table[(int)address];`, approx.size, strings.Join(table, ", "), cDouble(approx.min), cDouble(step), approx.size, approx.size-1), nil
+	}
+	return "", ErrCodeGeneration
+}
+
+// writeCModel writes the header and the source of a model into a directory creating it if needed
+func writeCModel(path string, c *cModel) error {
+	if !cIdentifier.MatchString(c.name) {
+		return ErrCodeGeneration
+	}
+	err := c.checkLayers()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = writeFile(filepath.Join(path, c.name+".h"), c.writeHeader)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(path, c.name+".c"), c.writeSource)
+}
+
+// writeHeader writes the declarations of a model
+func (c *cModel) writeHeader(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	inputs, outputs, _ := c.dimensions()
+	guard := strings.ToUpper(c.name) + "_H"
+	real := "double"
+	if c.precision == Float32Precision {
+		real = "float"
+	}
+
+	fmt.Fprintf(writer, "/* %s: %d layers, generated by gomlp */\n", c.name, len(inputs))
+	fmt.Fprintf(writer, "#ifndef %s\n#define %s\n\n", guard, guard)
+	if c.fixed != nil {
+		fmt.Fprintf(writer, "#include <stdint.h>\n\n")
+	}
+	fmt.Fprintf(writer, "#define %s_INPUTS %d\n", strings.ToUpper(c.name), inputs[0])
+	fmt.Fprintf(writer, "#define %s_OUTPUTS %d\n", strings.ToUpper(c.name), outputs[len(outputs)-1])
+	fmt.Fprintf(writer, "#define %s_CLASSES %d\n\n", strings.ToUpper(c.name), len(c.classes))
+	fmt.Fprintf(writer, "typedef %s %s_real;\n\n", real, c.name)
+	if len(c.classes) > 0 {
+		fmt.Fprintf(writer, "/* class labels indexed by the result of %s_predict */\n", c.name)
+		fmt.Fprintf(writer, "extern const double %s_classes[%s_CLASSES];\n\n", c.name, strings.ToUpper(c.name))
+	}
+	if c.fixed != nil {
+		fmt.Fprintf(writer, "/* raw fixed point inputs and outputs in %s */\n", c.fixed.config.activations)
+		fmt.Fprintf(writer, "void %s_quantize_input(const %s_real input[%s_INPUTS], int64_t raw[%s_INPUTS]);\n", c.name, c.name, strings.ToUpper(c.name), strings.ToUpper(c.name))
+		fmt.Fprintf(writer, "void %s_forward_raw(const int64_t input[%s_INPUTS], int64_t output[%s_OUTPUTS]);\n", c.name, strings.ToUpper(c.name), strings.ToUpper(c.name))
+	}
+	fmt.Fprintf(writer, "void %s_forward(const %s_real input[%s_INPUTS], %s_real output[%s_OUTPUTS]);\n", c.name, c.name, strings.ToUpper(c.name), c.name, strings.ToUpper(c.name))
+	fmt.Fprintf(writer, "int %s_predict(const %s_real input[%s_INPUTS]);\n\n", c.name, c.name, strings.ToUpper(c.name))
+	fmt.Fprintf(writer, "#endif\n")
+	return writer.Flush()
+}
+
+// writeSource writes the weights and the forward pass of a model
+func (c *cModel) writeSource(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	inputs, outputs, activations := c.dimensions()
+	upper := strings.ToUpper(c.name)
+	width := inputs[0]
+	for _, n := range outputs {
+		width = maxInt(width, n)
+	}
+
+	fmt.Fprintf(writer, "/* %s: %d layers, generated by gomlp */\n", c.name, len(inputs))
+	fmt.Fprintf(writer, "#include <math.h>\n#include \"%s.h\"\n\n", c.name)
+	fmt.Fprintf(writer, "#define %s_WIDTH %d\n\n", upper, width)
+	if len(c.classes) > 0 {
+		labels := make([]string, len(c.classes))
+		for i, class := range c.classes {
+			labels[i] = cDouble(class)
+		}
+		fmt.Fprintf(writer, "const double %s_classes[%s_CLASSES] = {%s};\n\n", c.name, upper, strings.Join(labels, ", "))
+	}
+
+	softmax := false
+	for i, a := range activations {
+		if a.mfunction != nil {
+			if a.name != Softmax {
+				return ErrCodeGeneration
+			}
+			softmax = true
+			continue
+		}
+		body, err := cActivation(a)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "/* %s */\nstatic double %s_activation_%d(double x)\n{\n\t%s\n}\n\n", a.name, c.name, i, body)
+	}
+	if softmax {
+		fmt.Fprintf(writer, "static void %s_softmax(double *values, int count)\n{\n", c.name)
+		fmt.Fprintf(writer, "\tdouble max = -INFINITY;\n\tdouble sum = 0.0;\n\tint i;\n")
+		fmt.Fprintf(writer, "\tfor (i = 0; i < count; i++) {\n\t\tmax = fmax(max, values[i]);\n\t}\n")
+		fmt.Fprintf(writer, "\tfor (i = 0; i < count; i++) {\n\t\tvalues[i] = exp(values[i] - max);\n\t\tsum = sum + values[i];\n\t}\n")
+		fmt.Fprintf(writer, "\tfor (i = 0; i < count; i++) {\n\t\tvalues[i] = values[i] / sum;\n\t}\n}\n\n")
+	}
+
+	var err error
+	if c.fixed != nil {
+		err = c.writeFixedSource(writer, inputs, outputs, activations)
+	} else {
+		err = c.writeFloatSource(writer, inputs, outputs, activations)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the same decision as Classifier.Predict
+	fmt.Fprintf(writer, "int %s_predict(const %s_real input[%s_INPUTS])\n{\n", c.name, c.name, upper)
+	fmt.Fprintf(writer, "\t%s_real output[%s_OUTPUTS];\n\tdouble max = -999.99;\n\tint best = 0;\n\tint i;\n", c.name, upper)
+	fmt.Fprintf(writer, "\t%s_forward(input, output);\n", c.name)
+	fmt.Fprintf(writer, "\tif (%s_OUTPUTS == 1) {\n", upper)
+	fmt.Fprintf(writer, "\t\tdouble floor_value = floor(output[0]);\n")
+	fmt.Fprintf(writer, "\t\treturn (int)floor_value + (output[0] > floor_value + %s ? 1 : 0);\n\t}\n", cDouble(precisionFactor))
+	fmt.Fprintf(writer, "\tfor (i = 0; i < %s_OUTPUTS; i++) {\n\t\tif (output[i] > max) {\n\t\t\tmax = output[i];\n\t\t\tbest = i;\n\t\t}\n\t}\n", upper)
+	fmt.Fprintf(writer, "\treturn best;\n}\n")
+	return writer.Flush()
+}
+
+// writeFloatSource writes the weights and the floating point forward pass of a model
+func (c *cModel) writeFloatSource(writer *bufio.Writer, inputs, outputs []int, activations []ActivationFunction) error {
+	upper := strings.ToUpper(c.name)
+	for i, layer := range c.layers {
+		rows := make([]string, layer.outputNodes)
+		bias := make([]string, layer.outputNodes)
+		for r, row := range layer.weights.data {
+			values := make([]string, len(row))
+			for col, weight := range row {
+				literal, err := c.cFloat(weight)
+				if err != nil {
+					return err
+				}
+				values[col] = literal
+			}
+			rows[r] = "\t{" + strings.Join(values, ", ") + "}"
+			literal, err := c.cFloat(layer.bias.data[r][0])
+			if err != nil {
+				return err
+			}
+			bias[r] = literal
+		}
+		fmt.Fprintf(writer, "static const %s_real %s_weights_%d[%d][%d] = {\n%s\n};\n", c.name, c.name, i, layer.outputNodes, layer.inputNodes, strings.Join(rows, ",\n"))
+		fmt.Fprintf(writer, "static const %s_real %s_bias_%d[%d] = {%s};\n\n", c.name, c.name, i, layer.outputNodes, strings.Join(bias, ", "))
+	}
+
+	fmt.Fprintf(writer, "void %s_forward(const %s_real input[%s_INPUTS], %s_real output[%s_OUTPUTS])\n{\n", c.name, c.name, upper, c.name, upper)
+	fmt.Fprintf(writer, "\t%s_real buffers[2][%s_WIDTH];\n\tdouble sums[%s_WIDTH];\n\tint i, j;\n", c.name, upper, upper)
+	fmt.Fprintf(writer, "\tfor (i = 0; i < %s_INPUTS; i++) {\n\t\tbuffers[0][i] = input[i];\n\t}\n", upper)
+	for l := range inputs {
+		in, out := l%2, (l+1)%2
+		fmt.Fprintf(writer, "\n\t/* layer %d: %d inputs, %d outputs, %s */\n", l, inputs[l], outputs[l], activations[l].name)
+		fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n\t\t%s_real sum = 0;\n", outputs[l], c.name)
+		fmt.Fprintf(writer, "\t\tfor (j = 0; j < %d; j++) {\n\t\t\tsum = sum + %s_weights_%d[i][j] * buffers[%d][j];\n\t\t}\n", inputs[l], c.name, l, in)
+		fmt.Fprintf(writer, "\t\tsums[i] = sum + %s_bias_%d[i];\n\t}\n", c.name, l)
+		if activations[l].mfunction != nil {
+			fmt.Fprintf(writer, "\t%s_softmax(sums, %d);\n", c.name, outputs[l])
+			fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n\t\tbuffers[%d][i] = (%s_real)sums[i];\n\t}\n", outputs[l], out, c.name)
+		} else {
+			fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n\t\tbuffers[%d][i] = (%s_real)%s_activation_%d(sums[i]);\n\t}\n", outputs[l], out, c.name, c.name, l)
+		}
+	}
+	fmt.Fprintf(writer, "\n\tfor (i = 0; i < %s_OUTPUTS; i++) {\n\t\toutput[i] = buffers[%d][i];\n\t}\n}\n\n", upper, len(inputs)%2)
+	return nil
+}
+
+// writeFixedSource writes the raw weights and the fixed point forward pass of a model
+func (c *cModel) writeFixedSource(writer *bufio.Writer, inputs, outputs []int, activations []ActivationFunction) error {
+	upper := strings.ToUpper(c.name)
+	config := c.fixed.config
+	fmt.Fprintf(writer, "/* weights %s, activations %s, accumulator %s, %s overflow, %s rounding */\n", config.weights, config.activations, config.accumulator, config.overflow, config.rounding)
+	for i, layer := range c.fixed.layers {
+		rows := make([]string, len(layer.weights))
+		bias := make([]string, len(layer.bias))
+		for r, row := range layer.weights {
+			values := make([]string, len(row))
+			for col, weight := range row {
+				values[col] = strconv.FormatInt(weight, 10)
+			}
+			rows[r] = "\t{" + strings.Join(values, ", ") + "}"
+			bias[r] = fmt.Sprintf("INT64_C(%d)", layer.bias[r])
+		}
+		fmt.Fprintf(writer, "static const %s %s_weights_%d[%d][%d] = {\n%s\n};\n", cInteger(config.weights.Width()), c.name, i, len(layer.weights), len(layer.weights[0]), strings.Join(rows, ",\n"))
+		fmt.Fprintf(writer, "static const int64_t %s_bias_%d[%d] = {%s};\n\n", c.name, i, len(layer.bias), strings.Join(bias, ", "))
+	}
+
+	// overflow of a raw value into a width
+	fmt.Fprintf(writer, "static int64_t %s_overflow(int64_t value, int width)\n{\n", c.name)
+	fmt.Fprintf(writer, "\tint64_t max = (int64_t)((UINT64_C(1) << (width - 1)) - 1);\n")
+	if config.overflow == Saturate {
+		fmt.Fprintf(writer, "\tif (value > max) {\n\t\treturn max;\n\t}\n\tif (value < -max - 1) {\n\t\treturn -max - 1;\n\t}\n\treturn value;\n}\n\n")
+	} else {
+		fmt.Fprintf(writer, "\tuint64_t mask = (UINT64_C(1) << width) - 1;\n\tuint64_t word = (uint64_t)value & mask;\n")
+		fmt.Fprintf(writer, "\tif (word > (uint64_t)max) {\n\t\tword = word | ~mask;\n\t}\n\treturn (int64_t)word;\n}\n\n")
+	}
+
+	// rounding of a real number to a raw value
+	roundings := map[RoundingMode]string{
+		Truncate:        "floor(scaled)",
+		RoundTowardZero: "trunc(scaled)",
+		RoundHalfUp:     "floor(scaled + 0.5)",
+		RoundHalfEven:   "nearbyint(scaled)",
+	}
+	fmt.Fprintf(writer, "static int64_t %s_quantize(double x, int fraction_bits, int width)\n{\n", c.name)
+	fmt.Fprintf(writer, "\tdouble scaled = %s;\n", strings.Replace(roundings[config.rounding], "scaled", "ldexp(x, fraction_bits)", -1))
+	fmt.Fprintf(writer, "\tif (isnan(scaled)) {\n\t\treturn 0;\n\t}\n")
+	fmt.Fprintf(writer, "\tif (scaled >= 9223372036854775808.0) {\n\t\treturn (int64_t)((UINT64_C(1) << (width - 1)) - 1);\n\t}\n")
+	fmt.Fprintf(writer, "\tif (scaled < -9223372036854775808.0) {\n\t\treturn -(int64_t)((UINT64_C(1) << (width - 1)) - 1) - 1;\n\t}\n")
+	fmt.Fprintf(writer, "\treturn %s_overflow((int64_t)scaled, width);\n}\n\n", c.name)
+
+	// rescaling of a raw value to fewer fraction bits using a floor division that does not rely on the
+	// implementation defined shift of negative values
+	fmt.Fprintf(writer, "static int64_t %s_rescale(int64_t value, int shift)\n{\n", c.name)
+	fmt.Fprintf(writer, "\tint64_t quotient;\n")
+	if config.rounding != Truncate {
+		fmt.Fprintf(writer, "\tint64_t remainder;\n")
+	}
+	if config.rounding == RoundHalfUp || config.rounding == RoundHalfEven {
+		fmt.Fprintf(writer, "\tint64_t half;\n")
+	}
+	fmt.Fprintf(writer, "\tif (shift <= 0) {\n\t\treturn value * ((int64_t)1 << -shift);\n\t}\n")
+	fmt.Fprintf(writer, "\tquotient = value >= 0 ? value >> shift : -((-(value + 1)) >> shift) - 1;\n")
+	if config.rounding != Truncate {
+		fmt.Fprintf(writer, "\tremainder = value - quotient * ((int64_t)1 << shift);\n")
+	}
+	if config.rounding == RoundHalfUp || config.rounding == RoundHalfEven {
+		fmt.Fprintf(writer, "\thalf = (int64_t)1 << (shift - 1);\n")
+	}
+	switch config.rounding {
+	case Truncate:
+		fmt.Fprintf(writer, "\treturn quotient;\n}\n\n")
+	case RoundTowardZero:
+		fmt.Fprintf(writer, "\treturn value < 0 && remainder != 0 ? quotient + 1 : quotient;\n}\n\n")
+	case RoundHalfUp:
+		fmt.Fprintf(writer, "\treturn remainder >= half ? quotient + 1 : quotient;\n}\n\n")
+	default:
+		fmt.Fprintf(writer, "\treturn remainder > half || (remainder == half && (quotient & 1) != 0) ? quotient + 1 : quotient;\n}\n\n")
+	}
+
+	fmt.Fprintf(writer, "void %s_quantize_input(const %s_real input[%s_INPUTS], int64_t raw[%s_INPUTS])\n{\n\tint i;\n", c.name, c.name, upper, upper)
+	fmt.Fprintf(writer, "\tfor (i = 0; i < %s_INPUTS; i++) {\n\t\traw[i] = %s_quantize(input[i], %d, %d);\n\t}\n}\n\n", upper, c.name, config.activations.fractionBits, config.activations.Width())
+
+	productShift := config.weights.fractionBits + config.activations.fractionBits - config.accumulator.fractionBits
+	accumulatorWidth := config.accumulator.Width()
+	fmt.Fprintf(writer, "void %s_forward_raw(const int64_t input[%s_INPUTS], int64_t output[%s_OUTPUTS])\n{\n", c.name, upper, upper)
+	fmt.Fprintf(writer, "\tint64_t buffers[2][%s_WIDTH];\n\tdouble reals[%s_WIDTH];\n\tint i, j;\n", upper, upper)
+	fmt.Fprintf(writer, "\tfor (i = 0; i < %s_INPUTS; i++) {\n\t\tbuffers[0][i] = input[i];\n\t}\n", upper)
+	for l := range inputs {
+		in, out := l%2, (l+1)%2
+		fmt.Fprintf(writer, "\n\t/* layer %d: %d inputs, %d outputs, %s */\n", l, inputs[l], outputs[l], activations[l].name)
+		fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n\t\tint64_t sum = %s_bias_%d[i];\n", outputs[l], c.name, l)
+		fmt.Fprintf(writer, "\t\tfor (j = 0; j < %d; j++) {\n", inputs[l])
+		fmt.Fprintf(writer, "\t\t\tint64_t product = %s_rescale((int64_t)%s_weights_%d[i][j] * buffers[%d][j], %d);\n", c.name, c.name, l, in, productShift)
+		fmt.Fprintf(writer, "\t\t\tsum = %s_overflow(sum + %s_overflow(product, %d), %d);\n\t\t}\n", c.name, c.name, accumulatorWidth, accumulatorWidth)
+		fmt.Fprintf(writer, "\t\treals[i] = ldexp((double)sum, %d);\n\t}\n", -1*config.accumulator.fractionBits)
+		if activations[l].mfunction != nil {
+			fmt.Fprintf(writer, "\t%s_softmax(reals, %d);\n", c.name, outputs[l])
+			fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n", outputs[l])
+			fmt.Fprintf(writer, "\t\tbuffers[%d][i] = %s_quantize(reals[i], %d, %d);\n\t}\n", out, c.name, config.activations.fractionBits, config.activations.Width())
+		} else {
+			fmt.Fprintf(writer, "\tfor (i = 0; i < %d; i++) {\n", outputs[l])
+			fmt.Fprintf(writer, "\t\tbuffers[%d][i] = %s_quantize(%s_activation_%d(reals[i]), %d, %d);\n\t}\n", out, c.name, c.name, l, config.activations.fractionBits, config.activations.Width())
+		}
+	}
+	fmt.Fprintf(writer, "\n\tfor (i = 0; i < %s_OUTPUTS; i++) {\n\t\toutput[i] = buffers[%d][i];\n\t}\n}\n\n", upper, len(inputs)%2)
+
+	fmt.Fprintf(writer, "void %s_forward(const %s_real input[%s_INPUTS], %s_real output[%s_OUTPUTS])\n{\n", c.name, c.name, upper, c.name, upper)
+	fmt.Fprintf(writer, "\tint64_t raw_input[%s_INPUTS];\n\tint64_t raw_output[%s_OUTPUTS];\n\tint i;\n", upper, upper)
+	fmt.Fprintf(writer, "\t%s_quantize_input(input, raw_input);\n\t%s_forward_raw(raw_input, raw_output);\n", c.name, c.name)
+	fmt.Fprintf(writer, "\tfor (i = 0; i < %s_OUTPUTS; i++) {\n\t\toutput[i] = ldexp((double)raw_output[i], %d);\n\t}\n}\n\n", upper, -1*config.activations.fractionBits)
+	return nil
+}
+
+// writeCTest writes a C program comparing the forward pass and the predictions of a model with expected values
+func writeCTest(path, name string, inputs int, tolerance float64, data [][]float64, outputs func([]float64) ([]float64, error), predict func([]float64) (int, error)) error {
+	if !cIdentifier.MatchString(name) {
+		return ErrCodeGeneration
+	}
+	if len(data) == 0 {
+		return ErrRowColumnDimension
+	}
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	inputRows := make([]string, len(data))
+	outputRows := make([]string, len(data))
+	classes := make([]string, len(data))
+	for i, row := range data {
+		if len(row) != inputs {
+			return ErrRowColumnDimension
+		}
+		values := make([]string, len(row))
+		for j, x := range row {
+			values[j] = cDouble(x)
+		}
+		inputRows[i] = "\t{" + strings.Join(values, ", ") + "}"
+
+		expected, err := outputs(row)
+		if err != nil {
+			return err
+		}
+		values = make([]string, len(expected))
+		for j, y := range expected {
+			values[j] = cDouble(y)
+		}
+		outputRows[i] = "\t{" + strings.Join(values, ", ") + "}"
+
+		class, err := predict(row)
+		if err != nil {
+			return err
+		}
+		classes[i] = strconv.Itoa(class)
+	}
+
+	upper := strings.ToUpper(name)
+	return writeFile(filepath.Join(path, name+"_test.c"), func(w io.Writer) error {
+		writer := bufio.NewWriter(w)
+		fmt.Fprintf(writer, "/* test vectors of %s, generated by gomlp */\n", name)
+		fmt.Fprintf(writer, "#include <math.h>\n#include <stdio.h>\n#include \"%s.h\"\n\n", name)
+		fmt.Fprintf(writer, "#define %s_ROWS %d\n#define %s_TOLERANCE %s\n\n", upper, len(data), upper, cDouble(tolerance))
+		fmt.Fprintf(writer, "static const double %s_test_inputs[%s_ROWS][%s_INPUTS] = {\n%s\n};\n", name, upper, upper, strings.Join(inputRows, ",\n"))
+		fmt.Fprintf(writer, "static const double %s_test_outputs[%s_ROWS][%s_OUTPUTS] = {\n%s\n};\n", name, upper, upper, strings.Join(outputRows, ",\n"))
+		fmt.Fprintf(writer, "static const int %s_test_classes[%s_ROWS] = {%s};\n\n", name, upper, strings.Join(classes, ", "))
+		fmt.Fprintf(writer, "int main(void)\n{\n\tint row, i;\n\tint failures = 0;\n\tdouble largest = 0.0;\n")
+		fmt.Fprintf(writer, "\tfor (row = 0; row < %s_ROWS; row++) {\n", upper)
+		fmt.Fprintf(writer, "\t\t%s_real input[%s_INPUTS];\n\t\t%s_real output[%s_OUTPUTS];\n\t\tint failed = 0;\n", name, upper, name, upper)
+		fmt.Fprintf(writer, "\t\tfor (i = 0; i < %s_INPUTS; i++) {\n\t\t\tinput[i] = (%s_real)%s_test_inputs[row][i];\n\t\t}\n", upper, name, name)
+		fmt.Fprintf(writer, "\t\t%s_forward(input, output);\n", name)
+		fmt.Fprintf(writer, "\t\tfor (i = 0; i < %s_OUTPUTS; i++) {\n", upper)
+		fmt.Fprintf(writer, "\t\t\tdouble difference = fabs((double)output[i] - %s_test_outputs[row][i]);\n", name)
+		fmt.Fprintf(writer, "\t\t\tlargest = difference > largest ? difference : largest;\n")
+		fmt.Fprintf(writer, "\t\t\tif (difference > %s_TOLERANCE) {\n\t\t\t\tfailed = 1;\n\t\t\t}\n\t\t}\n", upper)
+		fmt.Fprintf(writer, "\t\tif (%s_predict(input) != %s_test_classes[row]) {\n\t\t\tfailed = 1;\n\t\t}\n", name, name)
+		fmt.Fprintf(writer, "\t\tif (failed) {\n\t\t\tprintf(\"row %%d differs\\n\", row);\n\t\t\tfailures = failures + 1;\n\t\t}\n\t}\n")
+		fmt.Fprintf(writer, "\tprintf(\"%%d of %%d rows differ, largest output difference %%g\\n\", failures, %s_ROWS, largest);\n", upper)
+		fmt.Fprintf(writer, "\treturn failures == 0 ? 0 : 1;\n}\n")
+		return writer.Flush()
+	})
+}
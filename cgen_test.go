@@ -0,0 +1,99 @@
+package gomlp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// compileC builds and runs the C test program of a model when a C compiler is available
+func compileC(t *testing.T, dir, name string) {
+	t.Helper()
+	for _, file := range []string{name + ".h", name + ".c", name + "_test.c"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	compiler, err := exec.LookPath("cc")
+	if err != nil {
+		compiler, err = exec.LookPath("gcc")
+	}
+	if err != nil {
+		t.Skip("no C compiler to build the generated code")
+	}
+
+	program := filepath.Join(dir, name)
+	output, err := exec.Command(compiler, "-std=c99", "-Wall", "-Wextra", "-Werror", "-o", program, filepath.Join(dir, name+".c"), filepath.Join(dir, name+"_test.c"), "-lm").CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	output, err = exec.Command(program).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+}
+
+func TestWriteCMatchesPredict(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 12, 8, 3}, 10)
+	if err := mlp.SetActivations([]string{GELU, LeakyReLU, Softmax}); err != nil {
+		t.Fatal(err)
+	}
+	for name, precision := range map[string]BinaryPrecision{"model_double": Float64Precision, "model_float": Float32Precision} {
+		dir := t.TempDir()
+		if err := mlp.WriteC(dir, name, precision); err != nil {
+			t.Fatal(err)
+		}
+		if err := mlp.WriteCTestVectors(dir, name, precision, data); err != nil {
+			t.Fatal(err)
+		}
+		compileC(t, dir, name)
+	}
+}
+
+func TestFixedPointWriteCMatchesPredict(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 12, 8, 3}, 10)
+	if err := mlp.SetActivations([]string{LUTTanh, ReLU, Sigmoid}); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := NewFixedPointClassifier(mlp, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = fc.WriteC(dir, "model_fixed"); err != nil {
+		t.Fatal(err)
+	}
+	if err = fc.WriteCTestVectors(dir, "model_fixed", data); err != nil {
+		t.Fatal(err)
+	}
+	compileC(t, dir, "model_fixed")
+}
+
+func TestWriteCRejectsInvalidModels(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 4, 3}, 1)
+	if err := mlp.WriteC(t.TempDir(), "2model", Float64Precision); err != ErrCodeGeneration {
+		t.Errorf("got error %v, want %v", err, ErrCodeGeneration)
+	}
+	if err := mlp.WriteC(t.TempDir(), "model", 2); err != ErrBinaryPrecision {
+		t.Errorf("got error %v, want %v", err, ErrBinaryPrecision)
+	}
+
+	if err := mlp.SetQuantization(testFixedPointConfig(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mlp.WriteC(t.TempDir(), "model", Float64Precision); err != ErrCodeGeneration {
+		t.Errorf("got error %v, want %v", err, ErrCodeGeneration)
+	}
+	if err := mlp.WriteCTestVectors(t.TempDir(), "model", Float64Precision, data); err != ErrCodeGeneration {
+		t.Errorf("got error %v, want %v", err, ErrCodeGeneration)
+	}
+
+	fc := &FixedPointClassifier{&fixedPointNetwork{testFixedPointConfig(t), []fixedPointLayer{{weights: [][]int64{}, bias: []int64{}}}}, nil}
+	if err := fc.WriteC(t.TempDir(), "model"); err != ErrEmptyLayer {
+		t.Errorf("got error %v, want %v", err, ErrEmptyLayer)
+	}
+	if err := fc.WriteCTestVectors(t.TempDir(), "model", data); err != ErrEmptyLayer {
+		t.Errorf("got error %v, want %v", err, ErrEmptyLayer)
+	}
+}
@@ -1,289 +1,193 @@
 package gomlp
 
+import "io"
+
 // NewClassifier return a new pointer to the Classifier Class
 func NewClassifier(inputNodes, hiddenNodes, outputNodes int) (*Classifier, error) {
-	if inputNodes < 0 || outputNodes < 0 || hiddenNodes < 0 {
-		return &Classifier{}, ErrNodeValue
-	}
-
-	biasHidden, err := NewMatrix(hiddenNodes, 1)
-	if err != nil {
-		return &Classifier{}, err
-	}
-	biasOutput, err := NewMatrix(outputNodes, 1)
-	if err != nil {
-		return &Classifier{}, err
-	}
-	weightsInputHidden, err := NewMatrix(hiddenNodes, inputNodes)
-	if err != nil {
-		return &Classifier{}, err
-	}
-	weightsInputHidden.Randomize(2, 1)
+	return NewClassifierWithLayers([]int{inputNodes, hiddenNodes, outputNodes})
+}
 
-	weightsHiddenOutput, err := NewMatrix(outputNodes, hiddenNodes)
+// NewClassifierWithLayers return a new pointer to the Classifier Class with a layer for every size given
+func NewClassifierWithLayers(layerSizes []int, options ...Option) (*Classifier, error) {
+	nn, err := newNetwork(layerSizes, options...)
 	if err != nil {
 		return &Classifier{}, err
 	}
-	weightsHiddenOutput.Randomize(2, 1)
 
-	learningRate := 0.01
-	activationFunc := sigmoid
 	var classes []float64
 
 	return &Classifier{
-		inputNodes,
-		hiddenNodes,
-		outputNodes,
-		biasHidden,
-		biasOutput,
-		weightsInputHidden,
-		weightsHiddenOutput,
-		learningRate,
-		activationFunc,
+		nn,
 		classes,
 	}, nil
 }
 
 // NewClassifierFromFiles return a new pointer to the Classifier Class from CSV files
 func NewClassifierFromFiles(weightsInputHiddenFile, weightsHiddenOutputFile, biasHiddenFile, biasOutputFile string, stringHandler func(string) string) (*Classifier, error) {
-	weightsInputHiddenArr, err := ReadData(weightsInputHiddenFile, stringHandler)
-	if err != nil {
-		return &Classifier{}, err
-	}
-	weightsInputHidden, err := ConvertFromArray2DToMatrix(weightsInputHiddenArr)
-	if err != nil {
-		return &Classifier{}, err
-	}
+	return NewClassifierFromLayerFiles([]string{weightsInputHiddenFile, weightsHiddenOutputFile}, []string{biasHiddenFile, biasOutputFile}, stringHandler)
+}
 
-	weightsHiddenOutputArr, err := ReadData(weightsHiddenOutputFile, stringHandler)
+// NewClassifierFromLayerFiles return a new pointer to the Classifier Class from a weights and a bias CSV file per layer
+func NewClassifierFromLayerFiles(weightsFiles, biasFiles []string, stringHandler func(string) string) (*Classifier, error) {
+	layers, err := readLayers(weightsFiles, biasFiles, stringHandler)
 	if err != nil {
 		return &Classifier{}, err
 	}
-	weightsHiddenOutput, err := ConvertFromArray2DToMatrix(weightsHiddenOutputArr)
+
+	nn, err := newNetworkFromLayers(layers)
 	if err != nil {
 		return &Classifier{}, err
 	}
 
-	biasHiddenArr, err := ReadData(biasHiddenFile, stringHandler)
+	var classes []float64
+
+	return &Classifier{
+		nn,
+		classes,
+	}, nil
+}
+
+// NewClassifierFromDir return a new pointer to the Classifier Class from a directory written by SaveToDir
+// Directories holding the separate CSV files of earlier releases are migrated on load
+func NewClassifierFromDir(path string) (*Classifier, error) {
+	doc, err := readDocumentFromDir(path)
 	if err != nil {
 		return &Classifier{}, err
 	}
-	biasHidden, err := ConvertFromArray2DToMatrix(biasHiddenArr)
+	return newClassifierFromDocument(doc)
+}
+
+// LoadClassifier return a new pointer to the Classifier Class from a model file written by Save
+func LoadClassifier(r io.Reader) (*Classifier, error) {
+	doc, err := readDocument(r)
 	if err != nil {
 		return &Classifier{}, err
 	}
+	return newClassifierFromDocument(doc)
+}
 
-	biasOutputArr, err := ReadData(biasOutputFile, stringHandler)
-	if err != nil {
-		return &Classifier{}, err
+// newClassifierFromDocument return a new pointer to the Classifier Class described by a model file
+func newClassifierFromDocument(doc *savedModel) (*Classifier, error) {
+	if doc.Kind != "" && doc.Kind != ClassifierModel {
+		return &Classifier{}, ErrModelKind
 	}
-	biasOutput, err := ConvertFromArray2DToMatrix(biasOutputArr)
+
+	nn, err := newNetworkFromDocument(doc)
 	if err != nil {
 		return &Classifier{}, err
 	}
 
-	learningRate := 0.01
-	activationFunc := sigmoid
-
-	inputNodes := len(weightsInputHiddenArr[0])
-	hiddenNodes := len(weightsInputHiddenArr)
-	outputNodes := len(weightsHiddenOutputArr)
-	var classes []float64
+	classes := doc.Classes
 
 	return &Classifier{
-		inputNodes,
-		hiddenNodes,
-		outputNodes,
-		biasHidden,
-		biasOutput,
-		weightsInputHidden,
-		weightsHiddenOutput,
-		learningRate,
-		activationFunc,
+		nn,
 		classes,
 	}, nil
 }
 
 // Predict return a slice of the predicted output values of a trained neural network
 func (mlp *Classifier) Predict(inputArr []float64) (int, error) {
-	inputs, err := ConvertFromArrayToMatrix1D(inputArr)
-	if err != nil {
-		return 0, err
-	}
-	hidden, err := Multiply(mlp.weightsInputHidden, inputs)
+	output, err := mlp.predict(inputArr)
 	if err != nil {
 		return 0, err
 	}
-	hidden, err = Add(hidden, mlp.biasHidden)
-	if err != nil {
-		return 0, err
-	}
-
-	hidden.Map(mlp.activationFunc.function)
 
-	output, err := Multiply(mlp.weightsHiddenOutput, hidden)
-	if err != nil {
-		return 0, err
-	}
-	output, err = Add(output, mlp.biasOutput)
-	if err != nil {
-		return 0, err
-	}
+	return classifyOutputs(output.ConvertFromMatrixToArray1D()), nil
+}
 
-	output.Map(mlp.activationFunc.function)
-	if mlp.outputNodes > 1 {
-		return output.FindGreatestIndex(), nil
+// classifyOutputs returns the index of the largest output or the rounded single output
+func classifyOutputs(outputs []float64) int {
+	if len(outputs) > 1 {
+		m, _ := ConvertFromArrayToMatrix1D(outputs)
+		return m.FindGreatestIndex()
 	}
 
-	outputArr := GreatestIntegerFunction(output.ConvertFromMatrixToArray1D())
-	return outputArr[0], nil
+	outputArr := GreatestIntegerFunction(outputs)
+	return outputArr[0]
 }
 
-// Train is used to train a neural network
-func (mlp *Classifier) Train(data, targetArr [][]float64, epochs int) error {
-	mlp.Classes = ReturnTargetClasses(targetArr)
-	transformedTarget := TransformTargets(targetArr, mlp.Classes, mlp.outputNodes)
-	for iter := 0; iter < epochs; iter++ {
-		for range data {
-			index, inputArr := RandomDataSet(data)
-			inputs, err := ConvertFromArrayToMatrix1D(inputArr)
-			if err != nil {
-				return err
-			}
-			hidden, err := Multiply(mlp.weightsInputHidden, inputs)
-			if err != nil {
-				return err
-			}
-			hidden, err = Add(hidden, mlp.biasHidden)
-			if err != nil {
-				return err
-			}
-
-			hidden.Map(mlp.activationFunc.function)
-
-			output, err := Multiply(mlp.weightsHiddenOutput, hidden)
-			if err != nil {
-				return err
-			}
-			output, err = Add(output, mlp.biasOutput)
-			if err != nil {
-				return err
-			}
-
-			output.Map(mlp.activationFunc.function)
-			target, err := ConvertFromArrayToMatrix1D(transformedTarget[index])
-			if err != nil {
-				return err
-			}
-
-			outputError, err := Subtract(target, output)
-			if err != nil {
-				return err
-			}
-
-			gradients := Map(output, mlp.activationFunc.dfunction)
-			gradients, err = MapMultiply(gradients, outputError)
-			if err != nil {
-				return err
-			}
-			gradients.Multiply(mlp.learningRate)
-
-			hiddenT := hidden.Transpose()
-			weightHiddenOutputDeltas, err := Multiply(gradients, hiddenT)
-			if err != nil {
-				return err
-			}
-
-			mlp.weightsHiddenOutput, err = Add(mlp.weightsHiddenOutput, weightHiddenOutputDeltas)
-			if err != nil {
-				return err
-			}
-
-			mlp.biasOutput, err = Add(mlp.biasOutput, gradients)
-			if err != nil {
-				return err
-			}
-
-			weightsHiddenOutputT := mlp.weightsHiddenOutput.Transpose()
-			hiddenErrors, err := Multiply(weightsHiddenOutputT, gradients)
-			if err != nil {
-				return err
-			}
-
-			hiddenGradient := Map(hidden, mlp.activationFunc.dfunction)
-			hiddenGradient, err = MapMultiply(hiddenGradient, hiddenErrors)
-			if err != nil {
-				return err
-			}
-			hiddenGradient.Multiply(mlp.learningRate)
-
-			inputsT := inputs.Transpose()
-			weightsInputHiddenDeltas, err := Multiply(hiddenGradient, inputsT)
-			if err != nil {
-				return err
-			}
-			mlp.weightsInputHidden, err = Add(mlp.weightsInputHidden, weightsInputHiddenDeltas)
-			if err != nil {
-				return err
-			}
-			mlp.biasHidden, err = Add(mlp.biasHidden, hiddenGradient)
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	err := WriteData("weights_input_hidden.csv", mlp.weightsInputHidden.ConvertFromMatrixToArray2D())
+// PredictProbabilities return the output values of a trained neural network for a single input row
+// With a softmax output layer the values are the probabilities of every class
+func (mlp *Classifier) PredictProbabilities(inputArr []float64) ([]float64, error) {
+	output, err := mlp.predict(inputArr)
 	if err != nil {
-		return err
+		return []float64{}, err
 	}
+	return output.ConvertFromMatrixToArray1D(), nil
+}
 
-	err = WriteData("weights_hidden_output.csv", mlp.weightsHiddenOutput.ConvertFromMatrixToArray2D())
-	if err != nil {
-		return err
+// Train is used to train a neural network
+// With several output nodes every class including class 0 needs its own output node
+func (mlp *Classifier) Train(data, targetArr [][]float64, epochs int) error {
+	classes := ReturnTargetClasses(targetArr)
+	if outputs := mlp.outputNodes(); outputs > 1 && len(classes) > outputs {
+		return ErrTargetDimension
 	}
+	mlp.Classes = classes
+	transformedTarget := TransformTargets(targetArr, mlp.Classes, mlp.outputNodes())
+	return mlp.train(data, transformedTarget, epochs, mlp.SaveToDir)
+}
 
-	err = WriteData("bias_hidden.csv", mlp.biasHidden.ConvertFromMatrixToArray2D())
-	if err != nil {
-		return err
-	}
+// document returns the model file describing the classifier
+func (mlp *Classifier) document() *savedModel {
+	doc := mlp.network.document(ClassifierModel)
+	doc.Classes = mlp.Classes
+	return doc
+}
 
-	err = WriteData("bias_output.csv", mlp.biasOutput.ConvertFromMatrixToArray2D())
-	if err != nil {
-		return err
-	}
+// SaveToDir writes the model file into a directory creating it if needed
+func (mlp *Classifier) SaveToDir(path string) error {
+	return writeDocumentToDir(path, mlp.document())
+}
 
-	return nil
+// Save writes the model as a single versioned model file
+func (mlp *Classifier) Save(w io.Writer) error {
+	return writeDocument(w, mlp.document())
 }
 
 // Score return the various parameters of a Nerual Network used for checking efficiency and accuracy
 func (mlp *Classifier) Score(data [][]float64, target [][]float64) (float64, error) {
-	confusionMatrix := make([][]int, 2)
-	confusionMatrix[0] = make([]int, len(mlp.Classes))
-	confusionMatrix[1] = make([]int, len(mlp.Classes))
-	var score float64
+	return scoreClassifier(mlp.Predict, data, target)
+}
+
+// scoreClassifier return the share of rows whose predicted class matches the first column of the target
+func scoreClassifier(predict func([]float64) (int, error), data [][]float64, target [][]float64) (float64, error) {
+	if len(data) == 0 || len(data) != len(target) {
+		return 0, ErrRowColumnDimension
+	}
+
+	var accurate float64
 	for i, row := range data {
-		prediction, err := mlp.Predict(row)
+		prediction, err := predict(row)
 		if err != nil {
-			return score, err
+			return 0, err
 		}
 		if prediction == int(target[i][0]) {
-			pos := FindInArray(mlp.Classes, float64(prediction))
-			confusionMatrix[1][pos] = confusionMatrix[1][pos] + 1
-		} else {
-			pos := FindInArray(mlp.Classes, float64(prediction))
-			confusionMatrix[0][pos] = confusionMatrix[0][pos] + 1
+			accurate = accurate + 1
 		}
 	}
-	var total, accurate = 0.0, 0.0
-	for index, row := range confusionMatrix {
-		for _, element := range row {
-			if index == 1 {
-				accurate = accurate + float64(element)
-			}
-			total = total + float64(element)
+	return accurate / float64(len(data)), nil
+}
+
+// agreement return the share of rows where two predictors return the same class
+func agreement(predict, reference func([]float64) (int, error), data [][]float64) (float64, error) {
+	if len(data) == 0 {
+		return 0, ErrRowColumnDimension
+	}
+
+	var agreed float64
+	for _, row := range data {
+		a, err := predict(row)
+		if err != nil {
+			return 0, err
+		}
+		b, err := reference(row)
+		if err != nil {
+			return 0, err
+		}
+		if a == b {
+			agreed = agreed + 1
 		}
 	}
-	score = accurate / total
-	return score, nil
+	return agreed / float64(len(data)), nil
 }
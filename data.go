@@ -29,9 +29,7 @@ func PartitionData(data [][]float64, position string) ([][]float64, [][]float64)
 
 // RandomDataSet is used to provide random dataset values for training
 func RandomDataSet(data [][]float64) (int, []float64) {
-	max := len(data) - 1
-	min := 0
-	index := rand.Intn(max-min) + min
+	index := rand.Intn(len(data))
 	return index, data[index]
 }
 
@@ -45,6 +43,16 @@ func FindInArray(arr []float64, key float64) int {
 	return -1
 }
 
+// FindInStrings returns the position of a string in the slice
+func FindInStrings(arr []string, key string) int {
+	for index, element := range arr {
+		if element == key {
+			return index
+		}
+	}
+	return -1
+}
+
 // ReturnTargetClasses returns the various target classes sorted by value present in the target dataset
 func ReturnTargetClasses(target [][]float64) []float64 {
 	var classes = []float64{0}
@@ -13,4 +13,102 @@ var (
 	ErrRowColumnDimension = errors.New("Rows and Columns not of the same dimension")
 	// ErrMuliplicationDimension returns an error when number of rows and columns are not equal
 	ErrMuliplicationDimension = errors.New("Rows of the second matrix donot match the Columns of the first matrix")
+	// ErrLayerCount returns an error when a network has fewer than an input and an output layer
+	ErrLayerCount = errors.New("A network needs at least an input and an output layer")
+	// ErrLayerDimension returns an error when the outputs of a layer do not match the inputs of the next layer
+	ErrLayerDimension = errors.New("Outputs of a layer donot match the inputs of the next layer")
+	// ErrLayerFiles returns an error when the number of weights and bias files differ
+	ErrLayerFiles = errors.New("Number of weights files and bias files donot match")
+	// ErrActivationFunction returns an error when an activation function is registered without a name or functions
+	ErrActivationFunction = errors.New("Activation functions need a name, a function and a derivative")
+	// ErrActivationExists returns an error when an activation function is already registered under a name
+	ErrActivationExists = errors.New("An activation function is already registered under this name")
+	// ErrUnknownActivation returns an error when no activation function is registered under a name
+	ErrUnknownActivation = errors.New("No activation function is registered under this name")
+	// ErrLayerIndex returns an error when a layer index is not in range
+	ErrLayerIndex = errors.New("Layer index not in range")
+	// ErrActivationCount returns an error when the number of activation functions differs from the number of layers
+	ErrActivationCount = errors.New("Number of activation functions donot match the number of layers")
+	// ErrUnknownLoss returns an error when there is no loss function with a name
+	ErrUnknownLoss = errors.New("No loss function exists with this name")
+	// ErrTargetDimension returns an error when the targets do not match the number of output nodes
+	ErrTargetDimension = errors.New("Targets donot match the number of output nodes")
+	// ErrUnknownOptimizer returns an error when there is no optimizer with a name
+	ErrUnknownOptimizer = errors.New("No optimizer exists with this name")
+	// ErrOptimizerState returns an error when a state is restored into an optimizer of another kind
+	ErrOptimizerState = errors.New("Optimizer state belongs to a different optimizer")
+	// ErrBatchSize returns an error when the batch size is not positive
+	ErrBatchSize = errors.New("Batch size must be at least one")
+	// ErrSampler returns an error when no sampler is given
+	ErrSampler = errors.New("A sampler is required to train a network")
+	// ErrSamplerRows returns an error when a sampler is used on a dataset with a different number of rows
+	ErrSamplerRows = errors.New("Sampler was built for a different number of rows")
+	// ErrSamplerWeights returns an error when sampling weights are negative or all zero
+	ErrSamplerWeights = errors.New("Sampling weights must be non negative and not all zero")
+	// ErrRandomSource returns an error when no source of randomness is given
+	ErrRandomSource = errors.New("A source of randomness is required")
+	// ErrUnknownInitializer returns an error when there is no initialization scheme with a name
+	ErrUnknownInitializer = errors.New("No initialization scheme exists with this name")
+	// ErrInitializerParameters returns an error when an initialization scheme has the wrong number of parameters
+	ErrInitializerParameters = errors.New("Wrong number of parameters for the initialization scheme")
+	// ErrCheckpoint returns an error when checkpoints have a negative interval or no directory
+	ErrCheckpoint = errors.New("Checkpoints need a directory and a non negative interval")
+	// ErrUnknownPreprocessing returns an error when there is no preprocessing of a kind
+	ErrUnknownPreprocessing = errors.New("No preprocessing exists of this kind")
+	// ErrModelFormat returns an error when a file is not a model file
+	ErrModelFormat = errors.New("File is not a gomlp model file")
+	// ErrModelVersion returns an error when a model file has a version that cannot be read
+	ErrModelVersion = errors.New("Model file version is not supported")
+	// ErrModelKind returns an error when a model file holds a different kind of model
+	ErrModelKind = errors.New("Model file holds a different kind of model")
+	// ErrBinaryPrecision returns an error when a binary model file uses an unsupported floating point width
+	ErrBinaryPrecision = errors.New("Binary model files hold either float32 or float64 values")
+	// ErrChecksum returns an error when the checksum of a model file does not match its contents
+	ErrChecksum = errors.New("Model file checksum does not match its contents")
+	// ErrProtobuf returns an error when protocol buffer data is malformed
+	ErrProtobuf = errors.New("Malformed protocol buffer data")
+	// ErrONNXUnsupported returns an error when an ONNX model uses operators a network cannot represent
+	ErrONNXUnsupported = errors.New("ONNX model uses operators or activations that cannot be represented")
+	// ErrQFormat returns an error when a fixed point format has too few or too many bits
+	ErrQFormat = errors.New("Fixed point formats need at least the sign bit and at most 62 bits")
+	// ErrFixedPointMode returns an error when a fixed point configuration is missing or has unknown modes
+	ErrFixedPointMode = errors.New("Unknown fixed point overflow or rounding mode")
+	// ErrAccumulatorFormat returns an error when an accumulator has more fraction bits than the products it adds
+	ErrAccumulatorFormat = errors.New("Accumulator cannot have more fraction bits than the weights and activations together")
+	// ErrRNSModuli returns an error when residue number system moduli are not pairwise coprime or out of range
+	ErrRNSModuli = errors.New("Moduli must be pairwise coprime, below 2^31 and with a product below 2^62")
+	// ErrRNSResidues returns an error when residues do not match the moduli of a residue number system
+	ErrRNSResidues = errors.New("Number of residues donot match the number of moduli")
+	// ErrMemoryFormat returns an error when there is no memory initialization file format with a name
+	ErrMemoryFormat = errors.New("No memory initialization file format exists with this name")
+	// ErrEmptyLayer returns an error when a layer without inputs or outputs is exported
+	ErrEmptyLayer = errors.New("Exported layers need at least one input and one output")
+	// ErrApproximation returns an error when an approximation has no segments, no entries or an empty range
+	ErrApproximation = errors.New("Approximations need segments or table entries over a non empty range of a scalar function")
+	// ErrFaultRate returns an error when a fault rate is not a probability
+	ErrFaultRate = errors.New("Fault rate must be between zero and one")
+	// ErrFaultBits returns an error when a targeted bit position is outside a 64 bit word
+	ErrFaultBits = errors.New("Targeted bit positions must be between 0 and 63")
+	// ErrFaultTarget returns an error when faults are injected into an unknown or unsupported target
+	ErrFaultTarget = errors.New("Faults cannot be injected into this target")
+	// ErrFaultTrials returns an error when a fault campaign has no trials
+	ErrFaultTrials = errors.New("A fault campaign needs at least one trial")
+	// ErrParallelism returns an error when a hardware estimate has no parallel multipliers
+	ErrParallelism = errors.New("Parallelism must be at least one multiplier per layer")
+	// ErrActivationTable returns an error when the activation table of a hardware estimate is too wide to count
+	ErrActivationTable = errors.New("Activation format too wide for a table over every input")
+	// ErrSparsity returns an error when a target sparsity is not a share between zero and one
+	ErrSparsity = errors.New("Sparsity must be between zero and one")
+	// ErrPruneNeurons returns an error when pruning would remove a negative number or all the neurons of a layer
+	ErrPruneNeurons = errors.New("At least one neuron must remain in a pruned layer")
+	// ErrPruneSchedule returns an error when a pruning schedule has no steps
+	ErrPruneSchedule = errors.New("A pruning schedule needs at least one step")
+	// ErrCalibration returns an error when a calibration method or quantization granularity is unknown
+	ErrCalibration = errors.New("Unknown calibration method, percentile or quantization granularity")
+	// ErrInt8Unsupported returns an error when a hidden layer has an activation an int8 table cannot hold
+	ErrInt8Unsupported = errors.New("Hidden layers need a scalar activation function for int8 quantization")
+	// ErrCodeGeneration returns an error when a model cannot be written as C code
+	ErrCodeGeneration = errors.New("C code needs an identifier as name, finite float weights and built in or approximated activation functions")
+	// ErrVectorFormat returns an error when test vectors have an unknown file format or radix
+	ErrVectorFormat = errors.New("Test vectors are $readmemh, $readmemb or CSV files with a radix of 2, 8, 10 or 16")
 )
@@ -0,0 +1,199 @@
+package gomlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+// dspInputWidths are the widths of the two multiplier inputs of a DSP slice
+var dspInputWidths = [2]int{25, 18}
+
+// bramBits is the capacity of a single block RAM
+var bramBits = 36 * 1024
+
+// lutBits is the capacity of a single LUT used as a distributed ROM
+var lutBits = 64
+
+// linearActivations need no table as they are built from comparators and shifts
+var linearActivations = map[string]bool{
+	Identity:  true,
+	ReLU:      true,
+	LeakyReLU: true,
+}
+
+// estimatedArithmetic is the Data Structure to hold the datapath widths of the network being estimated
+// Fixed point has a single channel while a residue number system has a channel for every modulus
+type estimatedArithmetic struct {
+	multipliers [][2]int
+	adders      []int
+	weightBits  int
+	biasBits    int
+	conversion  int
+}
+
+// dsps returns the number of DSP slices needed by a multiplier of two widths
+func dsps(a, b int) int {
+	if a < b {
+		a, b = b, a
+	}
+	return ((a + dspInputWidths[0] - 1) / dspInputWidths[0]) * ((b + dspInputWidths[1] - 1) / dspInputWidths[1])
+}
+
+// ceilLog2 returns the number of halvings needed to reduce n to one
+func ceilLog2(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// estimateActivation adds the resources of the activation unit of a layer
+// Lookup table approximations store their entries, piecewise linear approximations compare the input against every
+// segment and evaluate the segment with a multiply add and any other activation function is a table over every input
+func estimateActivation(estimate *LayerResources, name string, width int) error {
+	if linearActivations[name] {
+		return nil
+	}
+
+	entries := 0
+	approx, ok := getApproximation(name)
+	switch {
+	case ok && approx.shape == lookupTableShape:
+		entries = approx.size
+	case ok:
+		// a slope and an intercept per segment, a comparator per segment boundary and the multiply add
+		entries = 2 * approx.size
+		estimate.DSPs = estimate.DSPs + dsps(width, width)
+		estimate.LUTs = estimate.LUTs + approx.size*width + 2*width
+	default:
+		if width+bits.Len(uint(width)) >= bits.UintSize-1 {
+			return ErrActivationTable
+		}
+		entries = 1 << uint(width)
+	}
+	estimate.ActivationTableBits = entries * width
+	estimate.LUTs = estimate.LUTs + (estimate.ActivationTableBits+lutBits-1)/lutBits
+	return nil
+}
+
+// EstimateResources returns an estimate of the FPGA resources and latency of the network in fixed point
+// Every layer has up to parallelism multipliers feeding an adder tree and an accumulator, evaluates its outputs
+// through a single activation unit and runs after the previous layer has finished
+func (nn *network) EstimateResources(config *FixedPointConfig, parallelism int) (*ResourceReport, error) {
+	if config == nil {
+		return &ResourceReport{}, ErrFixedPointMode
+	}
+	arith := estimatedArithmetic{
+		multipliers: [][2]int{{config.weights.Width(), config.activations.Width()}},
+		adders:      []int{config.accumulator.Width()},
+		weightBits:  config.weights.Width(),
+		biasBits:    config.accumulator.Width(),
+	}
+	arithmetic := fmt.Sprintf("fixed point, weights %s, activations %s, accumulator %s", config.weights, config.activations, config.accumulator)
+	return nn.estimate(arithmetic, arith, config.activations.Width(), parallelism)
+}
+
+// EstimateRNSResources returns an estimate of the FPGA resources and latency of the network in a residue number system
+// Every modulus has its own multiplier and adder tree per parallel lane and every output is reverse converted
+// by mixed radix conversion before the activation unit
+func (nn *network) EstimateRNSResources(system *RNS, config *FixedPointConfig, parallelism int) (*ResourceReport, error) {
+	if system == nil || len(system.moduli) == 0 {
+		return &ResourceReport{}, ErrRNSModuli
+	}
+	if config == nil {
+		return &ResourceReport{}, ErrFixedPointMode
+	}
+	arith := estimatedArithmetic{conversion: len(system.moduli)}
+	var moduli []string
+	for _, m := range system.moduli {
+		width := bits.Len64(uint64(m - 1))
+		arith.multipliers = append(arith.multipliers, [2]int{width, width})
+		arith.adders = append(arith.adders, width)
+		arith.weightBits = arith.weightBits + width
+		moduli = append(moduli, fmt.Sprint(m))
+	}
+	arith.biasBits = arith.weightBits
+	arithmetic := fmt.Sprintf("residue number system {%s}, weights %s, activations %s", strings.Join(moduli, ", "), config.weights, config.activations)
+	return nn.estimate(arithmetic, arith, config.activations.Width(), parallelism)
+}
+
+// estimate returns the resources of every layer and their totals
+func (nn *network) estimate(arithmetic string, arith estimatedArithmetic, activationWidth, parallelism int) (*ResourceReport, error) {
+	if parallelism < 1 {
+		return &ResourceReport{}, ErrParallelism
+	}
+
+	report := &ResourceReport{Arithmetic: arithmetic, Parallelism: parallelism}
+	for _, layer := range nn.layers {
+		weights := layer.inputNodes * layer.outputNodes
+		lanes := parallelism
+		if lanes > weights {
+			lanes = weights
+		}
+
+		estimate := LayerResources{
+			Inputs:         layer.inputNodes,
+			Outputs:        layer.outputNodes,
+			Activation:     layer.activationFunc.name,
+			Multipliers:    lanes * len(arith.multipliers),
+			AdderTreeDepth: ceilLog2(lanes),
+			WeightBits:     weights*arith.weightBits + layer.outputNodes*arith.biasBits,
+		}
+		for i, widths := range arith.multipliers {
+			estimate.DSPs = estimate.DSPs + lanes*dsps(widths[0], widths[1])
+			// one LUT per bit of every adder in the tree and of the accumulator
+			estimate.LUTs = estimate.LUTs + lanes*arith.adders[i]
+		}
+		err := estimateActivation(&estimate, layer.activationFunc.name, activationWidth)
+		if err != nil {
+			return &ResourceReport{}, err
+		}
+		// multiply accumulate passes, the adder tree, the activation unit and the reverse conversion
+		estimate.Cycles = estimate.AdderTreeDepth + 1 + arith.conversion
+		if lanes > 0 {
+			estimate.Cycles = estimate.Cycles + (weights+lanes-1)/lanes
+		}
+
+		report.Multipliers = report.Multipliers + estimate.Multipliers
+		report.DSPs = report.DSPs + estimate.DSPs
+		report.AdderTreeDepth = maxInt(report.AdderTreeDepth, estimate.AdderTreeDepth)
+		report.WeightBits = report.WeightBits + estimate.WeightBits
+		report.ActivationTableBits = report.ActivationTableBits + estimate.ActivationTableBits
+		report.LUTs = report.LUTs + estimate.LUTs
+		report.Cycles = report.Cycles + estimate.Cycles
+		report.Layers = append(report.Layers, estimate)
+	}
+	report.BRAMBlocks = (report.WeightBits + bramBits - 1) / bramBits
+	return report, nil
+}
+
+// maxInt returns the larger of two integers
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// String returns the report as a text table with a row for every layer and the totals
+func (r *ResourceReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, parallelism %d\n", r.Arithmetic, r.Parallelism)
+	fmt.Fprintf(&b, "%-8s %-10s %-12s %12s %8s %10s %12s %12s %8s %8s\n", "layer", "shape", "activation", "multipliers", "dsps", "tree depth", "weight bits", "table bits", "luts", "cycles")
+	for i, layer := range r.Layers {
+		fmt.Fprintf(&b, "%-8d %-10s %-12s %12d %8d %10d %12d %12d %8d %8d\n", i, fmt.Sprintf("%dx%d", layer.Inputs, layer.Outputs), layer.Activation, layer.Multipliers, layer.DSPs, layer.AdderTreeDepth, layer.WeightBits, layer.ActivationTableBits, layer.LUTs, layer.Cycles)
+	}
+	fmt.Fprintf(&b, "%-8s %-10s %-12s %12d %8d %10d %12d %12d %8d %8d\n", "total", "", "", r.Multipliers, r.DSPs, r.AdderTreeDepth, r.WeightBits, r.ActivationTableBits, r.LUTs, r.Cycles)
+	fmt.Fprintf(&b, "%d block RAMs of %d bits for the weights\n", r.BRAMBlocks, bramBits)
+	return b.String()
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *ResourceReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,124 @@
+package gomlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// testEstimatorNetwork returns a network of a layer of two inputs and four outputs and a layer of two outputs
+func testEstimatorNetwork(t *testing.T, activations []string) *network {
+	t.Helper()
+	nn, err := newNetwork([]int{2, 4, 2}, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = nn.SetActivations(activations); err != nil {
+		t.Fatal(err)
+	}
+	return nn
+}
+
+// testEstimatorConfig returns an eight bit datapath with a twenty bit accumulator
+func testEstimatorConfig(t *testing.T) *FixedPointConfig {
+	t.Helper()
+	q, _ := NewQFormat(4, 4)
+	accumulator, _ := NewQFormat(12, 8)
+	config, err := NewFixedPointConfig(q, q, accumulator, Saturate, RoundHalfEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestEstimateResources(t *testing.T) {
+	nn := testEstimatorNetwork(t, []string{ReLU, LUTSigmoid})
+	report, err := nn.EstimateResources(testEstimatorConfig(t), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LayerResources{
+		{Inputs: 2, Outputs: 4, Activation: ReLU, Multipliers: 4, DSPs: 4, AdderTreeDepth: 2, WeightBits: 8*8 + 4*20, LUTs: 4 * 20, Cycles: 5},
+		{Inputs: 4, Outputs: 2, Activation: LUTSigmoid, Multipliers: 4, DSPs: 4, AdderTreeDepth: 2, WeightBits: 8*8 + 2*20, ActivationTableBits: 256 * 8, LUTs: 4*20 + 256*8/64, Cycles: 5},
+	}
+	for i := range want {
+		if report.Layers[i] != want[i] {
+			t.Errorf("layer %d: got %+v, want %+v", i, report.Layers[i], want[i])
+		}
+	}
+	if report.Multipliers != 8 || report.Cycles != 10 || report.WeightBits != 248 || report.BRAMBlocks != 1 || report.LUTs != 192 {
+		t.Errorf("got totals %+v", report)
+	}
+
+	var buffer bytes.Buffer
+	if err = report.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ResourceReport
+	if err = json.Unmarshal(buffer.Bytes(), &decoded); err != nil || decoded.LUTs != report.LUTs || len(decoded.Layers) != 2 {
+		t.Errorf("got %+v with error %v", decoded, err)
+	}
+	if _, err = nn.EstimateResources(testEstimatorConfig(t), 0); err != ErrParallelism {
+		t.Errorf("got error %v, want %v", err, ErrParallelism)
+	}
+}
+
+func TestEstimateActivationUnits(t *testing.T) {
+	cases := []struct {
+		activation string
+		tableBits  int
+		dsps, luts int
+	}{
+		{Identity, 0, 0, 0},
+		{LUTTanh, 256 * 8, 0, 256 * 8 / 64},
+		{PiecewiseTanh, 2 * 8 * 8, 1, 8*8 + 2*8 + 2},
+		{PLANSigmoid, 2 * 4 * 8, 1, 4*8 + 2*8 + 1},
+		{Sigmoid, 256 * 8, 0, 256 * 8 / 64},
+	}
+	for _, tc := range cases {
+		var estimate LayerResources
+		if err := estimateActivation(&estimate, tc.activation, 8); err != nil {
+			t.Fatal(err)
+		}
+		if estimate.ActivationTableBits != tc.tableBits || estimate.DSPs != tc.dsps || estimate.LUTs != tc.luts {
+			t.Errorf("%s: got %+v", tc.activation, estimate)
+		}
+	}
+
+	var estimate LayerResources
+	if err := estimateActivation(&estimate, Sigmoid, 60); err != ErrActivationTable {
+		t.Errorf("got error %v, want %v", err, ErrActivationTable)
+	}
+	if err := estimateActivation(&estimate, PiecewiseTanh, 60); err != nil {
+		t.Errorf("got error %v for an approximation of a wide format", err)
+	}
+}
+
+func TestEstimateEmptyLayers(t *testing.T) {
+	nn := testEstimatorNetwork(t, []string{ReLU, ReLU})
+	nn.layers[1] = &Layer{activationFunc: nn.layers[1].activationFunc, weights: &Matrix{}, bias: &Matrix{}}
+	report, err := nn.EstimateResources(testEstimatorConfig(t), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer := report.Layers[1]; layer.Multipliers != 0 || layer.Cycles != 1 {
+		t.Errorf("got %+v", layer)
+	}
+}
+
+func TestEstimateRNSResources(t *testing.T) {
+	nn := testEstimatorNetwork(t, []string{ReLU, ReLU})
+	system, _ := NewSpecialRNS(3)
+	report, err := nn.EstimateRNSResources(system, testEstimatorConfig(t), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// moduli 7, 8 and 9 need channels of three, three and four bits and three reverse conversion steps
+	layer := report.Layers[0]
+	if layer.Multipliers != 6 || layer.WeightBits != 8*10+4*10 || layer.LUTs != 2*10 || layer.Cycles != 4+1+1+3 {
+		t.Errorf("got %+v", layer)
+	}
+	if _, err = nn.EstimateRNSResources(nil, testEstimatorConfig(t), 2); err != ErrRNSModuli {
+		t.Errorf("got error %v, want %v", err, ErrRNSModuli)
+	}
+}
@@ -19,10 +19,6 @@ func stringHandler(input string) string {
 	}
 }
 
-func dummyHandler(input string) string {
-	return input
-}
-
 func main() {
 	data, err := mlp.ReadData("ionosphere.csv", stringHandler)
 	if err != nil {
@@ -34,8 +30,13 @@ func main() {
 	normalizer.Fit(inputs)
 	normalized := normalizer.Transform(inputs, 1, -1)
 
-	brain, err := mlp.NewClassifierFromFiles("weights_input_hidden.csv", "weights_hidden_output.csv", "bias_hidden.csv", "bias_output.csv", dummyHandler)
-	// brain, err := mlp.NewClassifier(34, 10, 1)
+	brain, err := mlp.NewClassifierFromDir(".")
+	// brain, err := mlp.NewClassifierWithLayers([]int{34, 10, 1})
+	if err != nil {
+		panic(err)
+	}
+
+	err = brain.SetNormalizer(normalizer, 1, -1)
 	if err != nil {
 		panic(err)
 	}
@@ -45,6 +46,11 @@ func main() {
 		panic(err)
 	}
 
+	err = brain.SaveToDir(".")
+	if err != nil {
+		panic(err)
+	}
+
 	score, err := brain.Score(normalized, targets)
 	if err != nil {
 		panic(err)
@@ -28,17 +28,22 @@ func main() {
 	scalar.Fit(inputs)
 	scaled := scalar.Transform(inputs)
 
-	brain, err := mlp.NewClassifierFromFiles("weights_input_hidden.csv", "weights_hidden_output.csv", "bias_hidden.csv", "bias_output.csv", dummyHandler)
-	// brain, err := mlp.NewClassifier(28, 10, 6)
+	brain, err := mlp.NewClassifierFromDir(".")
+	// brain, err := mlp.NewClassifierWithLayers([]int{28, 10, 6})
+	// brain.SetActivations([]string{mlp.Sigmoid, mlp.Softmax})
+	// brain.SetLoss(mlp.CategoricalCrossEntropy)
 	if err != nil {
 		panic(err)
 	}
-	brain.Classes = mlp.ReturnTargetClasses(targets)
+	if len(brain.Classes) == 0 {
+		brain.Classes = mlp.ReturnTargetClasses(targets)
+	}
 
 	// err = brain.Train(scaled, targets, epochs)
 	// if err != nil {
 	// 	panic(err)
 	// }
+	// fmt.Println("Loss:", brain.LossHistory())
 
 	score, err := brain.Score(scaled, targets)
 	if err != nil {
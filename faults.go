@@ -0,0 +1,464 @@
+package gomlp
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"strings"
+)
+
+// FaultTarget is the kind of stored or intermediate value a fault is injected into
+type FaultTarget string
+
+// Targets of fault injection
+const (
+	// FaultWeights flips bits of the stored weights
+	FaultWeights FaultTarget = "weights"
+	// FaultBiases flips bits of the stored biases
+	FaultBiases FaultTarget = "biases"
+	// FaultActivations flips bits of the intermediate values of every prediction
+	// Float and fixed point networks are hit in the outputs of every layer and residue number system
+	// networks in the residues of the multiply accumulate results
+	FaultActivations FaultTarget = "activations"
+)
+
+// FaultInjectable is a model whose stored and intermediate values a FaultInjector can corrupt
+// It is implemented by Classifier, FixedPointClassifier and RNSClassifier
+type FaultInjectable interface {
+	Predict(inputArr []float64) (int, error)
+	faultLayers() int
+	faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error)
+}
+
+// NewFaultInjector return a new pointer to a fault injector flipping every exposed bit with a probability
+func NewFaultInjector(rate float64, source rand.Source) (*FaultInjector, error) {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return &FaultInjector{}, ErrFaultRate
+	}
+	if source == nil {
+		return &FaultInjector{}, ErrRandomSource
+	}
+
+	return &FaultInjector{
+		rate,
+		rand.New(source),
+		-1,
+		nil,
+	}, nil
+}
+
+// Rate returns the probability that any exposed bit flips
+func (fi *FaultInjector) Rate() float64 {
+	return fi.rate
+}
+
+// SetLayer restricts the faults to a single layer or to every layer with -1
+// A layer past the last layer of a model is reported when faults are injected into it
+func (fi *FaultInjector) SetLayer(layer int) error {
+	if layer < -1 {
+		return ErrLayerIndex
+	}
+	fi.layer = layer
+	return nil
+}
+
+// checkLayer returns an error if the injector is restricted to a layer a model with a number of layers does not have
+func (fi *FaultInjector) checkLayer(layers int) error {
+	if fi.layer >= layers {
+		return ErrLayerIndex
+	}
+	return nil
+}
+
+// SetBits restricts the faults to bit positions counted from the least significant bit
+// Calling it without positions exposes every bit again
+func (fi *FaultInjector) SetBits(positions ...int) error {
+	for _, position := range positions {
+		if position < 0 || position > 63 {
+			return ErrFaultBits
+		}
+	}
+	fi.bits = append([]int(nil), positions...)
+	return nil
+}
+
+// inLayer returns whether a layer is exposed to faults
+func (fi *FaultInjector) inLayer(layer int) bool {
+	return fi.layer < 0 || fi.layer == layer
+}
+
+// flip returns a word of a width with its exposed bits flipped at the fault rate and the number of flips
+func (fi *FaultInjector) flip(word uint64, width int) (uint64, int) {
+	var flips int
+	visit := func(position int) {
+		if position < width && fi.random.Float64() < fi.rate {
+			word = word ^ uint64(1)<<uint(position)
+			flips = flips + 1
+		}
+	}
+	if fi.bits != nil {
+		for _, position := range fi.bits {
+			visit(position)
+		}
+		return word, flips
+	}
+	for position := 0; position < width; position++ {
+		visit(position)
+	}
+	return word, flips
+}
+
+// FlipFloat returns a float64 with bits of its IEEE 754 representation flipped and the number of flips
+func (fi *FaultInjector) FlipFloat(x float64) (float64, int) {
+	word, flips := fi.flip(math.Float64bits(x), 64)
+	return math.Float64frombits(word), flips
+}
+
+// FlipWord returns a raw fixed point value with bits of its two's complement word flipped and the number of flips
+func (fi *FaultInjector) FlipWord(value int64, format QFormat) (int64, int) {
+	width := format.Width()
+	word, flips := fi.flip(format.Word(value, true), width)
+	shift := uint(64 - width)
+	return int64(word<<shift) >> shift, flips
+}
+
+// FlipResidue returns a residue with bits of its word flipped and the number of flips
+// The word holds just enough bits for the modulus so a flipped residue may be out of range as in hardware
+func (fi *FaultInjector) FlipResidue(residue, modulus int64) (int64, int) {
+	word, flips := fi.flip(uint64(residue), bits.Len64(uint64(modulus-1)))
+	return int64(word), flips
+}
+
+// flipMatrix flips the bits of every element of a Matrix in place and returns the number of flips
+func (fi *FaultInjector) flipMatrix(m *Matrix) int {
+	var flips int
+	for i := range m.data {
+		for j := range m.data[i] {
+			var n int
+			m.data[i][j], n = fi.FlipFloat(m.data[i][j])
+			flips = flips + n
+		}
+	}
+	return flips
+}
+
+// flipResidues flips the bits of every residue in place and returns the number of flips
+func (fi *FaultInjector) flipResidues(residues []int64, system *RNS) int {
+	var flips int
+	for i := range residues {
+		var n int
+		residues[i], n = fi.FlipResidue(residues[i], system.moduli[i])
+		flips = flips + n
+	}
+	return flips
+}
+
+// InjectFaults returns a copy of the classifier with bits of its weights or biases flipped and the number of flips
+func (mlp *Classifier) InjectFaults(injector *FaultInjector, target FaultTarget) (*Classifier, int, error) {
+	if target != FaultWeights && target != FaultBiases {
+		return &Classifier{}, 0, ErrFaultTarget
+	}
+	if err := injector.checkLayer(len(mlp.layers)); err != nil {
+		return &Classifier{}, 0, err
+	}
+
+	corrupted := mlp.clone()
+	var flips int
+	for i, layer := range corrupted.layers {
+		if injector.inLayer(i) {
+			if target == FaultWeights {
+				flips = flips + injector.flipMatrix(layer.weights)
+			} else {
+				flips = flips + injector.flipMatrix(layer.bias)
+			}
+		}
+	}
+	return corrupted, flips, nil
+}
+
+// clone returns a copy of the classifier with its own weights and biases for prediction
+func (mlp *Classifier) clone() *Classifier {
+	nn := *mlp.network
+	nn.layers = make([]*Layer, len(mlp.layers))
+	for i, layer := range mlp.layers {
+		copied := *layer
+		copied.weights = layer.weights.Copy()
+		copied.bias = layer.bias.Copy()
+		nn.layers[i] = &copied
+	}
+
+	return &Classifier{
+		&nn,
+		append([]float64(nil), mlp.Classes...),
+	}
+}
+
+// faultLayers returns the number of layers exposed to faults
+func (mlp *Classifier) faultLayers() int {
+	return len(mlp.layers)
+}
+
+// faulty returns a predictor of the classifier exposed to faults in a target counting the flips
+func (mlp *Classifier) faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error) {
+	if target != FaultActivations {
+		corrupted, n, err := mlp.InjectFaults(injector, target)
+		*flips = *flips + n
+		return corrupted.Predict, err
+	}
+
+	return func(inputArr []float64) (int, error) {
+		inputs, err := ConvertFromArrayToMatrix1D(inputArr)
+		if err != nil {
+			return 0, err
+		}
+		output := inputs
+		if mlp.quantization != nil {
+			output = mlp.quantization.fakeQuantize(inputs, mlp.quantization.activations)
+		}
+		for i, layer := range mlp.layers {
+			forward := layer.forward
+			if mlp.quantization != nil {
+				forward = mlp.quantization.forward(layer)
+			}
+			_, output, err = forward(output)
+			if err != nil {
+				return 0, err
+			}
+			if injector.inLayer(i) {
+				*flips = *flips + injector.flipMatrix(output)
+			}
+		}
+		return classifyOutputs(output.ConvertFromMatrixToArray1D()), nil
+	}, nil
+}
+
+// InjectFaults returns a copy of the fixed point classifier with bits of its raw weights or biases flipped
+// and the number of flips
+func (fc *FixedPointClassifier) InjectFaults(injector *FaultInjector, target FaultTarget) (*FixedPointClassifier, int, error) {
+	if target != FaultWeights && target != FaultBiases {
+		return &FixedPointClassifier{}, 0, ErrFaultTarget
+	}
+	if err := injector.checkLayer(len(fc.layers)); err != nil {
+		return &FixedPointClassifier{}, 0, err
+	}
+
+	fp := &fixedPointNetwork{fc.config, make([]fixedPointLayer, len(fc.layers))}
+	var flips int
+	for i, layer := range fc.layers {
+		weights := make([][]int64, len(layer.weights))
+		for r, row := range layer.weights {
+			weights[r] = append([]int64(nil), row...)
+		}
+		bias := append([]int64(nil), layer.bias...)
+		if injector.inLayer(i) {
+			for r := range weights {
+				var n int
+				if target == FaultWeights {
+					for c := range weights[r] {
+						weights[r][c], n = injector.FlipWord(weights[r][c], fc.config.weights)
+						flips = flips + n
+					}
+				} else {
+					bias[r], n = injector.FlipWord(bias[r], fc.config.accumulator)
+					flips = flips + n
+				}
+			}
+		}
+		fp.layers[i] = fixedPointLayer{weights, bias, layer.activationFunc}
+	}
+
+	return &FixedPointClassifier{
+		fp,
+		append([]float64(nil), fc.Classes...),
+	}, flips, nil
+}
+
+// faultLayers returns the number of layers exposed to faults
+func (fc *FixedPointClassifier) faultLayers() int {
+	return len(fc.layers)
+}
+
+// faulty returns a predictor of the fixed point classifier exposed to faults in a target counting the flips
+func (fc *FixedPointClassifier) faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error) {
+	if target != FaultActivations {
+		corrupted, n, err := fc.InjectFaults(injector, target)
+		*flips = *flips + n
+		return corrupted.Predict, err
+	}
+
+	corrupt := func(layer int, outputs []int64) {
+		if !injector.inLayer(layer) {
+			return
+		}
+		for r := range outputs {
+			var n int
+			outputs[r], n = injector.FlipWord(outputs[r], fc.config.activations)
+			*flips = *flips + n
+		}
+	}
+	return func(inputArr []float64) (int, error) {
+		_, activations, err := fc.forwardLayers(inputArr, corrupt)
+		if err != nil {
+			return 0, err
+		}
+		raw := activations[len(activations)-1]
+		outputs := make([]float64, len(raw))
+		for i, value := range raw {
+			outputs[i] = fc.config.activations.ToFloat(value)
+		}
+		return classifyOutputs(outputs), nil
+	}, nil
+}
+
+// InjectFaults returns a copy of the residue number system classifier with bits of the residues of its weights
+// or biases flipped and the number of flips
+func (rc *RNSClassifier) InjectFaults(injector *FaultInjector, target FaultTarget) (*RNSClassifier, int, error) {
+	if target != FaultWeights && target != FaultBiases {
+		return &RNSClassifier{}, 0, ErrFaultTarget
+	}
+	if err := injector.checkLayer(len(rc.layers)); err != nil {
+		return &RNSClassifier{}, 0, err
+	}
+
+	rn := *rc.rnsNetwork
+	rn.layers = make([]rnsLayer, len(rc.layers))
+	var flips int
+	for i, layer := range rc.layers {
+		weights := make([][][]int64, len(layer.weights))
+		bias := make([][]int64, len(layer.bias))
+		for r, row := range layer.weights {
+			weights[r] = make([][]int64, len(row))
+			for c, residues := range row {
+				weights[r][c] = append([]int64(nil), residues...)
+				if injector.inLayer(i) && target == FaultWeights {
+					flips = flips + injector.flipResidues(weights[r][c], rc.system)
+				}
+			}
+			bias[r] = append([]int64(nil), layer.bias[r]...)
+			if injector.inLayer(i) && target == FaultBiases {
+				flips = flips + injector.flipResidues(bias[r], rc.system)
+			}
+		}
+		rn.layers[i] = rnsLayer{weights, bias, layer.activationFunc}
+	}
+
+	return &RNSClassifier{
+		&rn,
+		append([]float64(nil), rc.Classes...),
+	}, flips, nil
+}
+
+// faultLayers returns the number of layers exposed to faults
+func (rc *RNSClassifier) faultLayers() int {
+	return len(rc.layers)
+}
+
+// faulty returns a predictor of the residue number system classifier exposed to faults in a target counting the flips
+func (rc *RNSClassifier) faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error) {
+	if target != FaultActivations {
+		corrupted, n, err := rc.InjectFaults(injector, target)
+		*flips = *flips + n
+		return corrupted.Predict, err
+	}
+
+	corrupt := func(layer int, sums [][]int64) {
+		if !injector.inLayer(layer) {
+			return
+		}
+		for _, residues := range sums {
+			*flips = *flips + injector.flipResidues(residues, rc.system)
+		}
+	}
+	return func(inputArr []float64) (int, error) {
+		_, activations, err := rc.forwardLayers(inputArr, corrupt)
+		if err != nil {
+			return 0, err
+		}
+		raw := activations[len(activations)-1]
+		outputs := make([]float64, len(raw))
+		for i, value := range raw {
+			outputs[i] = rc.config.activations.ToFloat(value)
+		}
+		return classifyOutputs(outputs), nil
+	}, nil
+}
+
+// RunFaultCampaign runs Monte Carlo trials of a model exposed to faults in a target over a test set
+// Every trial corrupts a fresh copy of the stored values or every prediction and measures the accuracy
+// The trials are then repeated with the faults restricted to each layer in turn to measure its sensitivity
+func RunFaultCampaign(model FaultInjectable, injector *FaultInjector, target FaultTarget, trials int, data, targets [][]float64) (FaultReport, error) {
+	if trials < 1 {
+		return FaultReport{}, ErrFaultTrials
+	}
+	if target != FaultWeights && target != FaultBiases && target != FaultActivations {
+		return FaultReport{}, ErrFaultTarget
+	}
+	if err := injector.checkLayer(model.faultLayers()); err != nil {
+		return FaultReport{}, err
+	}
+	baseline, err := scoreClassifier(model.Predict, data, targets)
+	if err != nil {
+		return FaultReport{}, err
+	}
+
+	report := FaultReport{
+		Target:           target,
+		Rate:             injector.rate,
+		Trials:           trials,
+		BaselineAccuracy: baseline,
+	}
+	report.MeanAccuracy, report.MinAccuracy, report.MeanFlips, err = runFaultTrials(model, injector, target, trials, data, targets)
+	if err != nil {
+		return FaultReport{}, err
+	}
+	report.AccuracyDrop = baseline - report.MeanAccuracy
+
+	layer := injector.layer
+	defer func() {
+		injector.layer = layer
+	}()
+	for i := 0; i < model.faultLayers(); i++ {
+		injector.layer = i
+		sensitivity := LayerSensitivity{Layer: i}
+		sensitivity.MeanAccuracy, _, sensitivity.MeanFlips, err = runFaultTrials(model, injector, target, trials, data, targets)
+		if err != nil {
+			return FaultReport{}, err
+		}
+		sensitivity.AccuracyDrop = baseline - sensitivity.MeanAccuracy
+		report.Layers = append(report.Layers, sensitivity)
+	}
+	return report, nil
+}
+
+// runFaultTrials returns the mean and the lowest accuracy and the mean number of flips over a number of trials
+func runFaultTrials(model FaultInjectable, injector *FaultInjector, target FaultTarget, trials int, data, targets [][]float64) (float64, float64, float64, error) {
+	var mean, flipsMean float64
+	min := math.Inf(1)
+	for t := 0; t < trials; t++ {
+		var flips int
+		predict, err := model.faulty(injector, target, &flips)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		accuracy, err := scoreClassifier(predict, data, targets)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		mean = mean + accuracy
+		min = math.Min(min, accuracy)
+		flipsMean = flipsMean + float64(flips)
+	}
+	return mean / float64(trials), min, flipsMean / float64(trials), nil
+}
+
+// String returns the report as text with a line for every layer
+func (r FaultReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "faults in %s at rate %g over %d trials\n", r.Target, r.Rate, r.Trials)
+	fmt.Fprintf(&b, "accuracy %.4f baseline, %.4f mean, %.4f lowest, %.4f drop, %.2f flips per trial\n", r.BaselineAccuracy, r.MeanAccuracy, r.MinAccuracy, r.AccuracyDrop, r.MeanFlips)
+	for _, layer := range r.Layers {
+		fmt.Fprintf(&b, "layer %d: accuracy %.4f mean, %.4f drop, %.2f flips per trial\n", layer.Layer, layer.MeanAccuracy, layer.AccuracyDrop, layer.MeanFlips)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package gomlp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFaultInjectorFlipsExposedBits(t *testing.T) {
+	always, err := NewFaultInjector(1, rand.NewSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _ := NewQFormat(4, 4)
+	if got, flips := always.FlipWord(0, q); got != -1 || flips != 8 {
+		t.Errorf("got %d after %d flips, want -1 after 8", got, flips)
+	}
+	if got, flips := always.FlipResidue(5, 7); got != 2 || flips != 3 {
+		t.Errorf("got %d after %d flips, want 2 after 3", got, flips)
+	}
+	if err = always.SetBits(63); err != nil {
+		t.Fatal(err)
+	}
+	if got, flips := always.FlipFloat(1.5); got != -1.5 || flips != 1 {
+		t.Errorf("got %v after %d flips, want -1.5 after 1", got, flips)
+	}
+
+	never, _ := NewFaultInjector(0, rand.NewSource(1))
+	if got, flips := never.FlipFloat(math.Pi); got != math.Pi || flips != 0 {
+		t.Errorf("got %v after %d flips", got, flips)
+	}
+
+	if _, err = NewFaultInjector(1.5, rand.NewSource(1)); err != ErrFaultRate {
+		t.Errorf("got error %v, want %v", err, ErrFaultRate)
+	}
+	if _, err = NewFaultInjector(0.5, nil); err != ErrRandomSource {
+		t.Errorf("got error %v, want %v", err, ErrRandomSource)
+	}
+	if err = always.SetBits(64); err != ErrFaultBits {
+		t.Errorf("got error %v, want %v", err, ErrFaultBits)
+	}
+}
+
+func TestFaultCampaign(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 8, 3}, 10)
+	never, _ := NewFaultInjector(0, rand.NewSource(1))
+	report, err := RunFaultCampaign(mlp, never, FaultWeights, 3, data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.AccuracyDrop != 0 || report.MeanFlips != 0 || len(report.Layers) != 2 {
+		t.Errorf("got report %s", report)
+	}
+
+	// flipping the sign and exponent bits of every weight destroys the classifier
+	always, _ := NewFaultInjector(1, rand.NewSource(1))
+	if err = always.SetBits(62); err != nil {
+		t.Fatal(err)
+	}
+	report, err = RunFaultCampaign(mlp, always, FaultWeights, 1, data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MeanFlips != 2*8+8*3 || report.AccuracyDrop <= 0 {
+		t.Errorf("got report %s", report)
+	}
+	if _, err = RunFaultCampaign(mlp, always, "memory", 1, data, target); err != ErrFaultTarget {
+		t.Errorf("got error %v, want %v", err, ErrFaultTarget)
+	}
+}
+
+func TestFaultInjectorRejectsMissingLayers(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 8, 3}, 1)
+	injector, _ := NewFaultInjector(0.5, rand.NewSource(1))
+	if err := injector.SetLayer(-2); err != ErrLayerIndex {
+		t.Errorf("got error %v, want %v", err, ErrLayerIndex)
+	}
+	if err := injector.SetLayer(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RunFaultCampaign(mlp, injector, FaultActivations, 1, data, target); err != ErrLayerIndex {
+		t.Errorf("got error %v, want %v", err, ErrLayerIndex)
+	}
+	if _, _, err := mlp.InjectFaults(injector, FaultWeights); err != ErrLayerIndex {
+		t.Errorf("got error %v, want %v", err, ErrLayerIndex)
+	}
+	fc, err := NewFixedPointClassifier(mlp, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = fc.InjectFaults(injector, FaultBiases); err != ErrLayerIndex {
+		t.Errorf("got error %v, want %v", err, ErrLayerIndex)
+	}
+
+	if err = injector.SetLayer(1); err != nil {
+		t.Fatal(err)
+	}
+	report, err := RunFaultCampaign(mlp, injector, FaultWeights, 1, data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MeanFlips == 0 {
+		t.Errorf("got report %s without flips in the last layer", report)
+	}
+}
@@ -25,16 +25,22 @@ func ReadData(filename string, stringHandler func(string) string) ([][]float64,
 
 	defer file.Close()
 
-	reader := csv.NewReader(bufio.NewReader(file))
-	if err != nil {
-		return data, err
-	}
+	return readData(bufio.NewReader(file), stringHandler)
+}
 
+// readData reads CSV data from a reader and returns the dataset
+func readData(r io.Reader, stringHandler func(string) string) ([][]float64, error) {
+	var data [][]float64
+
+	reader := csv.NewReader(r)
 	for {
 		row, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return data, err
+		}
 
 		dataRow := make([]float64, len(row))
 		for i := 0; i < len(row); i++ {
@@ -52,19 +58,22 @@ func ReadData(filename string, stringHandler func(string) string) ([][]float64,
 
 // WriteData writes data to a CSV file
 func WriteData(filename string, data [][]float64) error {
-	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-
 	if strings.Compare(filepath.Ext(filename), ".csv") != 0 {
 		return ErrOnlyCSVFiles
 	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return writeData(file, data)
+}
 
+// writeData writes data as CSV to a writer
+func writeData(w io.Writer, data [][]float64) error {
+	writer := csv.NewWriter(w)
 	for _, row := range data {
 		writeData := make([]string, len(row))
 		for i := 0; i < len(row); i++ {
@@ -75,5 +84,71 @@ func WriteData(filename string, data [][]float64) error {
 			return err
 		}
 	}
-	return err
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadNames reads a single row of names from a CSV file
+func ReadNames(filename string) ([]string, error) {
+	var names []string
+
+	if strings.Compare(filepath.Ext(filename), ".csv") != 0 {
+		return names, ErrOnlyCSVFiles
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return names, err
+	}
+	defer file.Close()
+
+	return readNames(bufio.NewReader(file))
+}
+
+// readNames reads a single row of names as CSV from a reader
+func readNames(r io.Reader) ([]string, error) {
+	return csv.NewReader(r).Read()
+}
+
+// WriteNames writes a single row of names to a CSV file
+func WriteNames(filename string, names []string) error {
+	if strings.Compare(filepath.Ext(filename), ".csv") != 0 {
+		return ErrOnlyCSVFiles
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeNames(file, names)
+}
+
+// writeNames writes a single row of names as CSV to a writer
+func writeNames(w io.Writer, names []string) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(names)
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeFile creates a file and writes it with a function returning the error of closing the file
+// so a write the disk could not complete is not reported as a success
+func writeFile(filename string, write func(io.Writer) error) (err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	return write(file)
 }
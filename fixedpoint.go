@@ -0,0 +1,356 @@
+package gomlp
+
+import "math"
+
+// OverflowMode decides what happens to fixed point values outside the range of their format
+type OverflowMode int
+
+// Overflow modes of fixed point arithmetic
+const (
+	// Saturate clamps values to the largest or smallest representable value
+	Saturate OverflowMode = iota
+	// Wrap keeps the low bits of values as two's complement hardware does
+	Wrap
+)
+
+// RoundingMode decides how fixed point values drop fraction bits
+type RoundingMode int
+
+// Rounding modes of fixed point arithmetic
+const (
+	// Truncate rounds toward negative infinity by dropping the low bits
+	Truncate RoundingMode = iota
+	// RoundTowardZero rounds toward zero
+	RoundTowardZero
+	// RoundHalfUp rounds to the nearest value with ties toward positive infinity
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value with ties to the even value
+	RoundHalfEven
+)
+
+var maxFixedPointBits = 62
+
+// NewQFormat return a signed fixed point format with the integer bits including the sign bit and the fraction bits
+func NewQFormat(integerBits, fractionBits int) (QFormat, error) {
+	if integerBits < 1 || fractionBits < 0 || integerBits+fractionBits > maxFixedPointBits {
+		return QFormat{}, ErrQFormat
+	}
+	return QFormat{integerBits, fractionBits}, nil
+}
+
+// NewFixedPointConfig return a new pointer to the fixed point formats and modes of an inference datapath
+// Products are rescaled to the accumulator format and biases are stored in it
+// The accumulator keeps at most the fraction bits of a product so rescaling never shifts a product left
+func NewFixedPointConfig(weights, activations, accumulator QFormat, overflow OverflowMode, rounding RoundingMode) (*FixedPointConfig, error) {
+	for _, format := range []QFormat{weights, activations, accumulator} {
+		if format.integerBits < 1 {
+			return &FixedPointConfig{}, ErrQFormat
+		}
+	}
+	if weights.Width()+activations.Width() > maxFixedPointBits {
+		return &FixedPointConfig{}, ErrQFormat
+	}
+	if accumulator.fractionBits > weights.fractionBits+activations.fractionBits {
+		return &FixedPointConfig{}, ErrAccumulatorFormat
+	}
+	if overflow != Saturate && overflow != Wrap {
+		return &FixedPointConfig{}, ErrFixedPointMode
+	}
+	if rounding < Truncate || rounding > RoundHalfEven {
+		return &FixedPointConfig{}, ErrFixedPointMode
+	}
+
+	return &FixedPointConfig{
+		weights,
+		activations,
+		accumulator,
+		overflow,
+		rounding,
+	}, nil
+}
+
+// IntegerBits returns the number of integer bits including the sign bit
+func (q QFormat) IntegerBits() int {
+	return q.integerBits
+}
+
+// FractionBits returns the number of fraction bits
+func (q QFormat) FractionBits() int {
+	return q.fractionBits
+}
+
+// Width returns the total number of bits of the format
+func (q QFormat) Width() int {
+	return q.integerBits + q.fractionBits
+}
+
+// Max returns the largest representable raw value
+func (q QFormat) Max() int64 {
+	return int64(1)<<uint(q.Width()-1) - 1
+}
+
+// Min returns the smallest representable raw value
+func (q QFormat) Min() int64 {
+	return -1 * int64(1) << uint(q.Width()-1)
+}
+
+// Overflow brings a raw value into the range of the format
+func (q QFormat) Overflow(value int64, overflow OverflowMode) int64 {
+	if overflow == Saturate {
+		if value > q.Max() {
+			return q.Max()
+		}
+		if value < q.Min() {
+			return q.Min()
+		}
+		return value
+	}
+
+	shift := uint(64 - q.Width())
+	return (value << shift) >> shift
+}
+
+// Quantize returns the raw value of the format closest to a real number under the rounding and overflow modes
+func (q QFormat) Quantize(x float64, rounding RoundingMode, overflow OverflowMode) int64 {
+	scaled := math.Ldexp(x, q.fractionBits)
+	switch rounding {
+	case Truncate:
+		scaled = math.Floor(scaled)
+	case RoundTowardZero:
+		scaled = math.Trunc(scaled)
+	case RoundHalfUp:
+		scaled = math.Floor(scaled + 0.5)
+	default:
+		scaled = math.RoundToEven(scaled)
+	}
+
+	limit := math.Ldexp(1, 63)
+	if math.IsNaN(scaled) {
+		return 0
+	}
+	if scaled >= limit {
+		return q.Overflow(math.MaxInt64, Saturate)
+	}
+	if scaled < -1*limit {
+		return q.Overflow(math.MinInt64, Saturate)
+	}
+	return q.Overflow(int64(scaled), overflow)
+}
+
+// ToFloat returns the real number of a raw value
+func (q QFormat) ToFloat(value int64) float64 {
+	return math.Ldexp(float64(value), -1*q.fractionBits)
+}
+
+// Weights returns the format of the weights
+func (c *FixedPointConfig) Weights() QFormat {
+	return c.weights
+}
+
+// Activations returns the format of the inputs and the outputs of every layer
+func (c *FixedPointConfig) Activations() QFormat {
+	return c.activations
+}
+
+// Accumulator returns the format of the biases and the multiply accumulate results
+func (c *FixedPointConfig) Accumulator() QFormat {
+	return c.accumulator
+}
+
+// Overflow returns the overflow mode of the datapath
+func (c *FixedPointConfig) Overflow() OverflowMode {
+	return c.overflow
+}
+
+// Rounding returns the rounding mode of the datapath
+func (c *FixedPointConfig) Rounding() RoundingMode {
+	return c.rounding
+}
+
+// rescale changes the number of fraction bits of a raw value using a rounding mode
+func rescale(value int64, from, to int, rounding RoundingMode) int64 {
+	if to >= from {
+		return value << uint(to-from)
+	}
+
+	shift := uint(from - to)
+	quotient := value >> shift
+	remainder := value - quotient<<shift
+	half := int64(1) << (shift - 1)
+	switch rounding {
+	case Truncate:
+		return quotient
+	case RoundTowardZero:
+		if value < 0 && remainder != 0 {
+			return quotient + 1
+		}
+		return quotient
+	case RoundHalfUp:
+		if remainder >= half {
+			return quotient + 1
+		}
+		return quotient
+	default:
+		if remainder > half || (remainder == half && quotient&1 == 1) {
+			return quotient + 1
+		}
+		return quotient
+	}
+}
+
+// newFixedPointNetwork return a new pointer to the network quantized to the formats of the config
+func newFixedPointNetwork(nn *network, config *FixedPointConfig) (*fixedPointNetwork, error) {
+	if config == nil {
+		return &fixedPointNetwork{}, ErrFixedPointMode
+	}
+
+	layers := make([]fixedPointLayer, len(nn.layers))
+	for i, layer := range nn.layers {
+		weights := make([][]int64, layer.outputNodes)
+		bias := make([]int64, layer.outputNodes)
+		for r := 0; r < layer.outputNodes; r++ {
+			weights[r] = make([]int64, layer.inputNodes)
+			for c := 0; c < layer.inputNodes; c++ {
+				weights[r][c] = config.weights.Quantize(layer.weights.data[r][c], config.rounding, config.overflow)
+			}
+			bias[r] = config.accumulator.Quantize(layer.bias.data[r][0], config.rounding, config.overflow)
+		}
+		layers[i] = fixedPointLayer{weights, bias, layer.activationFunc}
+	}
+
+	return &fixedPointNetwork{
+		config,
+		layers,
+	}, nil
+}
+
+// NewFixedPointClassifier return a new pointer to a trained Classifier quantized to fixed point
+func NewFixedPointClassifier(mlp *Classifier, config *FixedPointConfig) (*FixedPointClassifier, error) {
+	fp, err := newFixedPointNetwork(mlp.network, config)
+	if err != nil {
+		return &FixedPointClassifier{}, err
+	}
+
+	classes := append([]float64(nil), mlp.Classes...)
+
+	return &FixedPointClassifier{
+		fp,
+		classes,
+	}, nil
+}
+
+// Config returns the fixed point formats and modes of the network
+func (fp *fixedPointNetwork) Config() *FixedPointConfig {
+	return fp.config
+}
+
+// QuantizedWeights returns the raw fixed point weights and biases of a layer
+func (fp *fixedPointNetwork) QuantizedWeights(layer int) ([][]int64, []int64, error) {
+	if layer < 0 || layer >= len(fp.layers) {
+		return [][]int64{}, []int64{}, ErrLayerIndex
+	}
+
+	weights := make([][]int64, len(fp.layers[layer].weights))
+	for r, row := range fp.layers[layer].weights {
+		weights[r] = append([]int64(nil), row...)
+	}
+	return weights, append([]int64(nil), fp.layers[layer].bias...), nil
+}
+
+// QuantizeInput returns the raw fixed point values of an input row in the activation format
+func (fp *fixedPointNetwork) QuantizeInput(inputArr []float64) []int64 {
+	inputs := make([]int64, len(inputArr))
+	for i, x := range inputArr {
+		inputs[i] = fp.config.activations.Quantize(x, fp.config.rounding, fp.config.overflow)
+	}
+	return inputs
+}
+
+// ForwardLayers returns the raw accumulator values and the raw activation format outputs of every layer
+// Only the multiply accumulate path is integer arithmetic that a datapath repeats bit for bit
+// The activation functions are a float64 reference evaluated on the accumulator values and quantized to the
+// activation format so a datapath with its own activation tables matches the accumulators but may differ in the outputs
+// The outputs start with the quantized inputs and so hold one slice more than the accumulator values
+func (fp *fixedPointNetwork) ForwardLayers(inputArr []float64) ([][]int64, [][]int64, error) {
+	return fp.forwardLayers(inputArr, nil)
+}
+
+// forwardLayers returns the raw accumulator values and outputs of every layer
+// A non nil corrupt function may change the outputs of a layer before the next layer reads them
+func (fp *fixedPointNetwork) forwardLayers(inputArr []float64, corrupt func(layer int, outputs []int64)) ([][]int64, [][]int64, error) {
+	config := fp.config
+	activations := [][]int64{fp.QuantizeInput(inputArr)}
+	var accumulators [][]int64
+	productBits := config.weights.fractionBits + config.activations.fractionBits
+	for i, layer := range fp.layers {
+		inputs := activations[len(activations)-1]
+		if len(layer.weights) == 0 || len(inputs) != len(layer.weights[0]) {
+			return accumulators, activations, ErrRowColumnDimension
+		}
+
+		sums := make([]int64, len(layer.weights))
+		reals, _ := NewMatrix(len(layer.weights), 1)
+		for r, row := range layer.weights {
+			sum := layer.bias[r]
+			for c, weight := range row {
+				product := rescale(weight*inputs[c], productBits, config.accumulator.fractionBits, config.rounding)
+				sum = config.accumulator.Overflow(sum+config.accumulator.Overflow(product, config.overflow), config.overflow)
+			}
+			sums[r] = sum
+			reals.data[r][0] = config.accumulator.ToFloat(sum)
+		}
+
+		outputs := make([]int64, len(sums))
+		for r, value := range layer.activationFunc.apply(reals).ConvertFromMatrixToArray1D() {
+			outputs[r] = config.activations.Quantize(value, config.rounding, config.overflow)
+		}
+		if corrupt != nil {
+			corrupt(i, outputs)
+		}
+		accumulators = append(accumulators, sums)
+		activations = append(activations, outputs)
+	}
+	return accumulators, activations, nil
+}
+
+// Forward returns the raw activation format outputs of the network for a single input row
+func (fp *fixedPointNetwork) Forward(inputArr []float64) ([]int64, error) {
+	_, activations, err := fp.forwardLayers(inputArr, nil)
+	if err != nil {
+		return []int64{}, err
+	}
+	return activations[len(activations)-1], nil
+}
+
+// Outputs returns the outputs of the network for a single input row converted back to real numbers
+func (fp *fixedPointNetwork) Outputs(inputArr []float64) ([]float64, error) {
+	raw, err := fp.Forward(inputArr)
+	if err != nil {
+		return []float64{}, err
+	}
+	outputs := make([]float64, len(raw))
+	for i, value := range raw {
+		outputs[i] = fp.config.activations.ToFloat(value)
+	}
+	return outputs, nil
+}
+
+// Predict return the predicted class index of the fixed point network in the same way as Classifier.Predict
+// The activation functions are a float64 reference as described for ForwardLayers
+func (fc *FixedPointClassifier) Predict(inputArr []float64) (int, error) {
+	outputs, err := fc.Outputs(inputArr)
+	if err != nil {
+		return 0, err
+	}
+	return classifyOutputs(outputs), nil
+}
+
+// Score return the accuracy of the fixed point network in the same way as Classifier.Score
+func (fc *FixedPointClassifier) Score(data [][]float64, target [][]float64) (float64, error) {
+	return scoreClassifier(fc.Predict, data, target)
+}
+
+// Agreement return the share of rows where the fixed point network predicts the same class as a Classifier
+func (fc *FixedPointClassifier) Agreement(mlp *Classifier, data [][]float64) (float64, error) {
+	return agreement(fc.Predict, mlp.Predict, data)
+}
@@ -0,0 +1,148 @@
+package gomlp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQFormatOverflowAtTheBoundaries(t *testing.T) {
+	q, err := NewQFormat(4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Max() != 127 || q.Min() != -128 {
+		t.Fatalf("got range [%d, %d], want [-128, 127]", q.Min(), q.Max())
+	}
+	cases := []struct {
+		value              int64
+		saturated, wrapped int64
+	}{
+		{127, 127, 127},
+		{128, 127, -128},
+		{129, 127, -127},
+		{255, 127, -1},
+		{256, 127, 0},
+		{-128, -128, -128},
+		{-129, -128, 127},
+		{-256, -128, 0},
+		{math.MaxInt64, 127, -1},
+		{math.MinInt64, -128, 0},
+	}
+	for _, tc := range cases {
+		if got := q.Overflow(tc.value, Saturate); got != tc.saturated {
+			t.Errorf("saturate %d: got %d, want %d", tc.value, got, tc.saturated)
+		}
+		if got := q.Overflow(tc.value, Wrap); got != tc.wrapped {
+			t.Errorf("wrap %d: got %d, want %d", tc.value, got, tc.wrapped)
+		}
+	}
+
+	// real numbers just past the range saturate or wrap like their raw values
+	if got := q.Quantize(8, Truncate, Saturate); got != 127 {
+		t.Errorf("got %d, want 127", got)
+	}
+	if got := q.Quantize(8, Truncate, Wrap); got != -128 {
+		t.Errorf("got %d, want -128", got)
+	}
+	if got := q.Quantize(-8.0625, Truncate, Saturate); got != -128 {
+		t.Errorf("got %d, want -128", got)
+	}
+	if got := q.Quantize(math.Inf(1), Truncate, Wrap); got != 127 {
+		t.Errorf("got %d, want 127", got)
+	}
+	if got := q.Quantize(math.NaN(), Truncate, Saturate); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+}
+
+func TestRoundingModesOnTies(t *testing.T) {
+	q, _ := NewQFormat(8, 0)
+	cases := []struct {
+		value float64
+		want  map[RoundingMode]int64
+	}{
+		{2.5, map[RoundingMode]int64{Truncate: 2, RoundTowardZero: 2, RoundHalfUp: 3, RoundHalfEven: 2}},
+		{3.5, map[RoundingMode]int64{Truncate: 3, RoundTowardZero: 3, RoundHalfUp: 4, RoundHalfEven: 4}},
+		{-2.5, map[RoundingMode]int64{Truncate: -3, RoundTowardZero: -2, RoundHalfUp: -2, RoundHalfEven: -2}},
+		{-3.5, map[RoundingMode]int64{Truncate: -4, RoundTowardZero: -3, RoundHalfUp: -3, RoundHalfEven: -4}},
+		{-0.5, map[RoundingMode]int64{Truncate: -1, RoundTowardZero: 0, RoundHalfUp: 0, RoundHalfEven: 0}},
+		{2.75, map[RoundingMode]int64{Truncate: 2, RoundTowardZero: 2, RoundHalfUp: 3, RoundHalfEven: 3}},
+		{-2.25, map[RoundingMode]int64{Truncate: -3, RoundTowardZero: -2, RoundHalfUp: -2, RoundHalfEven: -2}},
+	}
+	for _, tc := range cases {
+		for rounding, want := range tc.want {
+			if got := q.Quantize(tc.value, rounding, Saturate); got != want {
+				t.Errorf("quantize %v in mode %d: got %d, want %d", tc.value, rounding, got, want)
+			}
+			// the same value with two fraction bits rescaled to none rounds the same way
+			if got := rescale(int64(tc.value*4), 2, 0, rounding); got != want {
+				t.Errorf("rescale %v in mode %d: got %d, want %d", tc.value, rounding, got, want)
+			}
+		}
+	}
+	if got := rescale(-3, 1, 3, Truncate); got != -12 {
+		t.Errorf("got %d, want -12", got)
+	}
+}
+
+func TestFixedPointConfigValidation(t *testing.T) {
+	q4, _ := NewQFormat(4, 4)
+	q8, _ := NewQFormat(8, 8)
+	wide, _ := NewQFormat(8, 12)
+	if _, err := NewFixedPointConfig(q4, q4, wide, Saturate, RoundHalfEven); err != ErrAccumulatorFormat {
+		t.Errorf("got error %v, want %v", err, ErrAccumulatorFormat)
+	}
+	if _, err := NewFixedPointConfig(q4, q4, q8, Wrap+1, RoundHalfEven); err != ErrFixedPointMode {
+		t.Errorf("got error %v, want %v", err, ErrFixedPointMode)
+	}
+	if _, err := NewFixedPointConfig(q4, q4, q8, Saturate, RoundHalfEven+1); err != ErrFixedPointMode {
+		t.Errorf("got error %v, want %v", err, ErrFixedPointMode)
+	}
+	for _, bits := range [][2]int{{0, 4}, {4, -1}, {40, 40}} {
+		if _, err := NewQFormat(bits[0], bits[1]); err != ErrQFormat {
+			t.Errorf("%v: got error %v, want %v", bits, err, ErrQFormat)
+		}
+	}
+}
+
+// testFixedPointConfig returns a sixteen bit datapath with a wide accumulator
+func testFixedPointConfig(t *testing.T) *FixedPointConfig {
+	t.Helper()
+	weights, _ := NewQFormat(4, 12)
+	activations, _ := NewQFormat(4, 12)
+	accumulator, _ := NewQFormat(16, 16)
+	config, err := NewFixedPointConfig(weights, activations, accumulator, Saturate, RoundHalfEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestFixedPointClassifierAgrees(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 3}, 30)
+	fc, err := NewFixedPointClassifier(mlp, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agreement, err := fc.Agreement(mlp, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agreement < 0.97 {
+		t.Errorf("got agreement %v, want at least 0.97", agreement)
+	}
+	if _, err = fc.Score(data, target); err != nil {
+		t.Fatal(err)
+	}
+
+	accumulators, activations, err := fc.ForwardLayers(data[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accumulators) != 3 || len(activations) != 4 || len(activations[3]) != 3 {
+		t.Errorf("got %d accumulators and %d activations", len(accumulators), len(activations))
+	}
+	if _, err = fc.Forward([]float64{1}); err != ErrRowColumnDimension {
+		t.Errorf("got error %v, want %v", err, ErrRowColumnDimension)
+	}
+}
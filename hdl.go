@@ -0,0 +1,307 @@
+package gomlp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MemoryFormat is the file format of a memory initialization file
+type MemoryFormat string
+
+// Formats of memory initialization files
+const (
+	// ReadMemH writes one hexadecimal word per line for the Verilog $readmemh task
+	ReadMemH MemoryFormat = "readmemh"
+	// COE writes Xilinx coefficient files
+	COE MemoryFormat = "coe"
+	// MIF writes Intel memory initialization files
+	MIF MemoryFormat = "mif"
+	// VHDLPackage writes a single VHDL package with a constant array for every memory
+	VHDLPackage MemoryFormat = "vhdl"
+)
+
+var memoryExtensions = map[MemoryFormat]string{
+	ReadMemH:    ".hex",
+	COE:         ".coe",
+	MIF:         ".mif",
+	VHDLPackage: ".vhd",
+}
+
+var vhdlPackageName = "gomlp_weights"
+
+var manifestFile = "manifest.json"
+
+// memoryLayout describes how the weights of a layer are laid out in memory
+var memoryLayout = "row major, address = output * inputs + input"
+
+// hdlManifest is the Data Structure to hold the description of exported memories
+type hdlManifest struct {
+	Format       MemoryFormat       `json:"format"`
+	Signed       bool               `json:"signed"`
+	Encoding     string             `json:"encoding"`
+	Overflow     string             `json:"overflow"`
+	Rounding     string             `json:"rounding"`
+	Activations  hdlManifestFormat  `json:"activations"`
+	Accumulator  hdlManifestFormat  `json:"accumulator"`
+	Classes      []float64          `json:"classes,omitempty"`
+	Layers       []hdlManifestLayer `json:"layers"`
+	VHDLPackage  string             `json:"vhdl_package,omitempty"`
+	MemoryLayout string             `json:"memory_layout"`
+}
+
+// hdlManifestLayer is the Data Structure to hold the description of the memories of a layer
+type hdlManifestLayer struct {
+	Inputs     int               `json:"inputs"`
+	Outputs    int               `json:"outputs"`
+	Activation string            `json:"activation"`
+	Weights    hdlManifestMemory `json:"weights"`
+	Bias       hdlManifestMemory `json:"bias"`
+}
+
+// hdlManifestMemory is the Data Structure to hold the description of a single memory
+type hdlManifestMemory struct {
+	File  string `json:"file,omitempty"`
+	Name  string `json:"name"`
+	Depth int    `json:"depth"`
+	hdlManifestFormat
+}
+
+// hdlManifestFormat is the Data Structure to hold the description of a fixed point format
+type hdlManifestFormat struct {
+	Width        int `json:"width"`
+	IntegerBits  int `json:"integer_bits"`
+	FractionBits int `json:"fraction_bits"`
+}
+
+// hdlMemory is the Data Structure to hold the words of a single memory
+type hdlMemory struct {
+	name   string
+	format QFormat
+	words  []uint64
+}
+
+// String returns the name of the overflow mode
+func (o OverflowMode) String() string {
+	if o == Wrap {
+		return "wrap"
+	}
+	return "saturate"
+}
+
+// String returns the name of the rounding mode
+func (r RoundingMode) String() string {
+	switch r {
+	case Truncate:
+		return "truncate"
+	case RoundTowardZero:
+		return "toward_zero"
+	case RoundHalfUp:
+		return "half_up"
+	default:
+		return "half_even"
+	}
+}
+
+// Word returns the bits of a raw value as a memory word of the width of the format
+// Signed words are two's complement and unsigned words are offset binary with zero at half the range
+func (q QFormat) Word(value int64, signed bool) uint64 {
+	if !signed {
+		value = value - q.Min()
+	}
+	return uint64(value) & (uint64(1)<<uint(q.Width()) - 1)
+}
+
+// memories returns the weight and bias memories of every layer as words
+func (fp *fixedPointNetwork) memories(signed bool) [][2]hdlMemory {
+	memories := make([][2]hdlMemory, len(fp.layers))
+	for i, layer := range fp.layers {
+		weights := hdlMemory{fmt.Sprintf("layer_%d_weights", i), fp.config.weights, nil}
+		for _, row := range layer.weights {
+			for _, value := range row {
+				weights.words = append(weights.words, fp.config.weights.Word(value, signed))
+			}
+		}
+		bias := hdlMemory{fmt.Sprintf("layer_%d_bias", i), fp.config.accumulator, nil}
+		for _, value := range layer.bias {
+			bias.words = append(bias.words, fp.config.accumulator.Word(value, signed))
+		}
+		memories[i] = [2]hdlMemory{weights, bias}
+	}
+	return memories
+}
+
+// WriteMemories writes the quantized weights and biases of every layer as memory initialization files and a manifest
+// Weights use the weights format of the config and biases the accumulator format
+func (fc *FixedPointClassifier) WriteMemories(path string, format MemoryFormat, signed bool) error {
+	return fc.writeMemories(path, format, signed, fc.Classes)
+}
+
+// writeMemories writes the memory files and the manifest into a directory creating it if needed
+func (fp *fixedPointNetwork) writeMemories(path string, format MemoryFormat, signed bool, classes []float64) error {
+	extension, ok := memoryExtensions[format]
+	if !ok {
+		return ErrMemoryFormat
+	}
+	for _, layer := range fp.layers {
+		if len(layer.weights) == 0 || len(layer.weights[0]) == 0 {
+			return ErrEmptyLayer
+		}
+	}
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	config := fp.config
+	manifest := hdlManifest{
+		Format:       format,
+		Signed:       signed,
+		Encoding:     "two's complement",
+		Overflow:     config.overflow.String(),
+		Rounding:     config.rounding.String(),
+		Activations:  config.activations.manifest(),
+		Accumulator:  config.accumulator.manifest(),
+		Classes:      classes,
+		MemoryLayout: memoryLayout,
+	}
+	if !signed {
+		manifest.Encoding = "offset binary"
+	}
+
+	memories := fp.memories(signed)
+	for i, layer := range fp.layers {
+		entry := hdlManifestLayer{
+			Inputs:     len(layer.weights[0]),
+			Outputs:    len(layer.weights),
+			Activation: layer.activationFunc.Name(),
+		}
+		for j, memory := range memories[i] {
+			described := hdlManifestMemory{Name: memory.name, Depth: len(memory.words), hdlManifestFormat: memory.format.manifest()}
+			if format != VHDLPackage {
+				described.File = memory.name + extension
+				err = writeMemoryFile(filepath.Join(path, described.File), format, memory)
+				if err != nil {
+					return err
+				}
+			}
+			if j == 0 {
+				entry.Weights = described
+			} else {
+				entry.Bias = described
+			}
+		}
+		manifest.Layers = append(manifest.Layers, entry)
+	}
+
+	if format == VHDLPackage {
+		manifest.VHDLPackage = vhdlPackageName + extension
+		err = writeFile(filepath.Join(path, manifest.VHDLPackage), func(w io.Writer) error {
+			return writeVHDLPackage(w, fp, memories, signed)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeFile(filepath.Join(path, manifestFile), func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	})
+}
+
+// manifest returns the description of the format for a manifest
+func (q QFormat) manifest() hdlManifestFormat {
+	return hdlManifestFormat{q.Width(), q.integerBits, q.fractionBits}
+}
+
+// writeMemoryFile writes a single memory into a file
+func writeMemoryFile(filename string, format MemoryFormat, memory hdlMemory) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return writeMemory(w, format, memory)
+	})
+}
+
+// writeMemory writes a single memory as a $readmemh, COE or MIF file
+func writeMemory(w io.Writer, format MemoryFormat, memory hdlMemory) error {
+	writer := bufio.NewWriter(w)
+	width := memory.format.Width()
+	digits := (width + 3) / 4
+	description := fmt.Sprintf("%s: %d words of %d bits, Q%d.%d", memory.name, len(memory.words), width, memory.format.integerBits, memory.format.fractionBits)
+
+	switch format {
+	case ReadMemH:
+		fmt.Fprintf(writer, "// %s\n", description)
+		for _, word := range memory.words {
+			fmt.Fprintf(writer, "%0*x\n", digits, word)
+		}
+	case COE:
+		fmt.Fprintf(writer, "; %s\n", description)
+		fmt.Fprintf(writer, "memory_initialization_radix=16;\n")
+		fmt.Fprintf(writer, "memory_initialization_vector=\n")
+		for i, word := range memory.words {
+			separator := ","
+			if i == len(memory.words)-1 {
+				separator = ";"
+			}
+			fmt.Fprintf(writer, "%0*x%s\n", digits, word, separator)
+		}
+	case MIF:
+		fmt.Fprintf(writer, "-- %s\n", description)
+		fmt.Fprintf(writer, "WIDTH=%d;\nDEPTH=%d;\n\n", width, len(memory.words))
+		fmt.Fprintf(writer, "ADDRESS_RADIX=UNS;\nDATA_RADIX=HEX;\n\n")
+		fmt.Fprintf(writer, "CONTENT BEGIN\n")
+		for i, word := range memory.words {
+			fmt.Fprintf(writer, "\t%d : %0*X;\n", i, digits, word)
+		}
+		fmt.Fprintf(writer, "END;\n")
+	default:
+		return ErrMemoryFormat
+	}
+	return writer.Flush()
+}
+
+// writeVHDLPackage writes every memory as a constant array of a VHDL package
+func writeVHDLPackage(w io.Writer, fp *fixedPointNetwork, memories [][2]hdlMemory, signed bool) error {
+	writer := bufio.NewWriter(w)
+	kind := "signed"
+	if !signed {
+		kind = "unsigned"
+	}
+
+	fmt.Fprintf(writer, "-- Quantized weights and biases, %s\n", memoryLayout)
+	fmt.Fprintf(writer, "library ieee;\nuse ieee.std_logic_1164.all;\nuse ieee.numeric_std.all;\n\n")
+	fmt.Fprintf(writer, "package %s is\n", vhdlPackageName)
+	fmt.Fprintf(writer, "\tconstant LAYER_COUNT : natural := %d;\n", len(fp.layers))
+	fmt.Fprintf(writer, "\tconstant WEIGHT_WIDTH : natural := %d;\n", fp.config.weights.Width())
+	fmt.Fprintf(writer, "\tconstant WEIGHT_FRACTION_BITS : natural := %d;\n", fp.config.weights.fractionBits)
+	fmt.Fprintf(writer, "\tconstant BIAS_WIDTH : natural := %d;\n", fp.config.accumulator.Width())
+	fmt.Fprintf(writer, "\tconstant BIAS_FRACTION_BITS : natural := %d;\n", fp.config.accumulator.fractionBits)
+	fmt.Fprintf(writer, "\tconstant ACTIVATION_WIDTH : natural := %d;\n", fp.config.activations.Width())
+	fmt.Fprintf(writer, "\tconstant ACTIVATION_FRACTION_BITS : natural := %d;\n", fp.config.activations.fractionBits)
+	for i, layer := range fp.layers {
+		fmt.Fprintf(writer, "\n\tconstant LAYER_%d_INPUTS : natural := %d;\n", i, len(layer.weights[0]))
+		fmt.Fprintf(writer, "\tconstant LAYER_%d_OUTPUTS : natural := %d;\n", i, len(layer.weights))
+		for _, memory := range memories[i] {
+			name := strings.ToUpper(memory.name)
+			width := memory.format.Width()
+			fmt.Fprintf(writer, "\ttype %s_t is array (0 to %d) of %s(%d downto 0);\n", memory.name, len(memory.words)-1, kind, width-1)
+			fmt.Fprintf(writer, "\tconstant %s : %s_t := (\n", name, memory.name)
+			for j, word := range memory.words {
+				separator := ","
+				if j == len(memory.words)-1 {
+					separator = ""
+				}
+				fmt.Fprintf(writer, "\t\t%d => \"%0*b\"%s\n", j, width, word, separator)
+			}
+			fmt.Fprintf(writer, "\t);\n")
+		}
+	}
+	fmt.Fprintf(writer, "end package %s;\n", vhdlPackageName)
+	return writer.Flush()
+}
@@ -0,0 +1,130 @@
+package gomlp
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWordEncodings(t *testing.T) {
+	q, _ := NewQFormat(2, 2)
+	cases := []struct {
+		value            int64
+		signed, unsigned uint64
+	}{
+		{-8, 0x8, 0x0},
+		{-1, 0xf, 0x7},
+		{0, 0x0, 0x8},
+		{7, 0x7, 0xf},
+	}
+	for _, tc := range cases {
+		if got := q.Word(tc.value, true); got != tc.signed {
+			t.Errorf("signed %d: got %x, want %x", tc.value, got, tc.signed)
+		}
+		if got := q.Word(tc.value, false); got != tc.unsigned {
+			t.Errorf("unsigned %d: got %x, want %x", tc.value, got, tc.unsigned)
+		}
+	}
+}
+
+// readMemH returns the words of a $readmemh file as raw values of a format
+func readMemH(t *testing.T, filename string, q QFormat, signed bool) []int64 {
+	t.Helper()
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var values []int64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "//") {
+			continue
+		}
+		word, err := strconv.ParseUint(line, 16, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shift := uint(64 - q.Width())
+		value := int64(word<<shift) >> shift
+		if !signed {
+			value = int64(word) + q.Min()
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestWriteMemoriesRoundTrip(t *testing.T) {
+	mlp, _, _ := testClassifier(t, []int{2, 6, 3}, 3)
+	fc, err := NewFixedPointClassifier(mlp, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signed := range []bool{true, false} {
+		dir := t.TempDir()
+		if err = fc.WriteMemories(dir, ReadMemH, signed); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var manifest hdlManifest
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Layers) != 2 || manifest.Signed != signed {
+			t.Fatalf("got manifest %+v", manifest)
+		}
+		for i, layer := range manifest.Layers {
+			weights, bias, _ := fc.QuantizedWeights(i)
+			var want []int64
+			for _, row := range weights {
+				want = append(want, row...)
+			}
+			got := readMemH(t, filepath.Join(dir, layer.Weights.File), fc.config.weights, signed)
+			if len(got) != layer.Weights.Depth || len(got) != layer.Inputs*layer.Outputs {
+				t.Fatalf("layer %d: got %d weights, want %d", i, len(got), layer.Inputs*layer.Outputs)
+			}
+			for j := range want {
+				if got[j] != want[j] {
+					t.Fatalf("layer %d weight %d: got %d, want %d", i, j, got[j], want[j])
+				}
+			}
+			got = readMemH(t, filepath.Join(dir, layer.Bias.File), fc.config.accumulator, signed)
+			for j := range bias {
+				if got[j] != bias[j] {
+					t.Fatalf("layer %d bias %d: got %d, want %d", i, j, got[j], bias[j])
+				}
+			}
+		}
+	}
+
+	for _, format := range []MemoryFormat{COE, MIF, VHDLPackage} {
+		dir := t.TempDir()
+		if err = fc.WriteMemories(dir, format, true); err != nil {
+			t.Fatal(err)
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "*"+memoryExtensions[format]))
+		if len(files) == 0 {
+			t.Errorf("%s: no memory files written", format)
+		}
+	}
+	if err = fc.WriteMemories(t.TempDir(), "bin", true); err != ErrMemoryFormat {
+		t.Errorf("got error %v, want %v", err, ErrMemoryFormat)
+	}
+}
+
+func TestWriteMemoriesRejectsEmptyLayers(t *testing.T) {
+	fp := &fixedPointNetwork{testFixedPointConfig(t), []fixedPointLayer{{weights: [][]int64{}, bias: []int64{}}}}
+	if err := fp.writeMemories(t.TempDir(), ReadMemH, true, nil); err != ErrEmptyLayer {
+		t.Errorf("got error %v, want %v", err, ErrEmptyLayer)
+	}
+}
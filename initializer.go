@@ -0,0 +1,235 @@
+package gomlp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Names of the weight initialization schemes
+const (
+	ZerosInitializer         = "zeros"
+	ConstantInitializer      = "constant"
+	UniformInitializer       = "uniform"
+	NormalInitializer        = "normal"
+	GlorotUniformInitializer = "glorot_uniform"
+	GlorotNormalInitializer  = "glorot_normal"
+	HeUniformInitializer     = "he_uniform"
+	HeNormalInitializer      = "he_normal"
+	LeCunUniformInitializer  = "lecun_uniform"
+	LeCunNormalInitializer   = "lecun_normal"
+	OrthogonalInitializer    = "orthogonal"
+)
+
+// Zeros returns an Initializer setting every value to zero
+func Zeros() Initializer {
+	return Initializer{ZerosInitializer, nil}
+}
+
+// Constant returns an Initializer setting every value to the given value
+func Constant(value float64) Initializer {
+	return Initializer{ConstantInitializer, []float64{value}}
+}
+
+// Uniform returns an Initializer drawing every value uniformly from [min, max)
+func Uniform(min, max float64) Initializer {
+	return Initializer{UniformInitializer, []float64{min, max}}
+}
+
+// Normal returns an Initializer drawing every value from a normal distribution
+func Normal(mean, stddev float64) Initializer {
+	return Initializer{NormalInitializer, []float64{mean, stddev}}
+}
+
+// GlorotUniform returns an Initializer drawing uniformly within sqrt(6 / (fanIn + fanOut))
+func GlorotUniform() Initializer {
+	return Initializer{GlorotUniformInitializer, nil}
+}
+
+// GlorotNormal returns an Initializer drawing from a normal distribution with deviation sqrt(2 / (fanIn + fanOut))
+func GlorotNormal() Initializer {
+	return Initializer{GlorotNormalInitializer, nil}
+}
+
+// HeUniform returns an Initializer drawing uniformly within sqrt(6 / fanIn)
+func HeUniform() Initializer {
+	return Initializer{HeUniformInitializer, nil}
+}
+
+// HeNormal returns an Initializer drawing from a normal distribution with deviation sqrt(2 / fanIn)
+func HeNormal() Initializer {
+	return Initializer{HeNormalInitializer, nil}
+}
+
+// LeCunUniform returns an Initializer drawing uniformly within sqrt(3 / fanIn)
+func LeCunUniform() Initializer {
+	return Initializer{LeCunUniformInitializer, nil}
+}
+
+// LeCunNormal returns an Initializer drawing from a normal distribution with deviation sqrt(1 / fanIn)
+func LeCunNormal() Initializer {
+	return Initializer{LeCunNormalInitializer, nil}
+}
+
+// Orthogonal returns an Initializer producing a scaled matrix with orthonormal rows or columns
+func Orthogonal(gain float64) Initializer {
+	return Initializer{OrthogonalInitializer, []float64{gain}}
+}
+
+// ParseInitializer returns the Initializer described by a string in the form returned by String
+// An empty description stands for a layer whose initialization scheme was not recorded
+func ParseInitializer(description string) (Initializer, error) {
+	if description == "" {
+		return Initializer{}, nil
+	}
+
+	name := description
+	var params []float64
+	if open := strings.Index(description, "("); open != -1 {
+		if !strings.HasSuffix(description, ")") {
+			return Initializer{}, ErrUnknownInitializer
+		}
+		name = description[:open]
+		for _, field := range strings.Split(description[open+1:len(description)-1], ",") {
+			param, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return Initializer{}, err
+			}
+			params = append(params, param)
+		}
+	}
+
+	var expected int
+	switch name {
+	case ZerosInitializer, GlorotUniformInitializer, GlorotNormalInitializer, HeUniformInitializer, HeNormalInitializer, LeCunUniformInitializer, LeCunNormalInitializer:
+		expected = 0
+	case ConstantInitializer, OrthogonalInitializer:
+		expected = 1
+	case UniformInitializer, NormalInitializer:
+		expected = 2
+	default:
+		return Initializer{}, ErrUnknownInitializer
+	}
+	if len(params) != expected {
+		return Initializer{}, ErrInitializerParameters
+	}
+
+	return Initializer{name, params}, nil
+}
+
+// Name returns the name of the initialization scheme
+func (in Initializer) Name() string {
+	return in.name
+}
+
+// Parameters returns the parameters of the initialization scheme
+func (in Initializer) Parameters() []float64 {
+	params := make([]float64, len(in.params))
+	copy(params, in.params)
+	return params
+}
+
+// String returns the name of the initialization scheme followed by its parameters in brackets
+func (in Initializer) String() string {
+	if len(in.params) == 0 {
+		return in.name
+	}
+
+	params := make([]string, len(in.params))
+	for i, param := range in.params {
+		params[i] = strconv.FormatFloat(param, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%s(%s)", in.name, strings.Join(params, ","))
+}
+
+// Initialize sets the values of a Matrix with as many columns as inputs and as many rows as outputs
+func (in Initializer) Initialize(m *Matrix, random *rand.Rand) error {
+	fanIn := float64(m.cols)
+	fanOut := float64(m.rows)
+	switch in.name {
+	case ZerosInitializer:
+		m.fill(func() float64 { return 0 })
+	case ConstantInitializer:
+		m.fill(func() float64 { return in.params[0] })
+	case UniformInitializer:
+		m.RandomizeWithRand(in.params[1], in.params[0], random)
+	case NormalInitializer:
+		m.fill(func() float64 { return random.NormFloat64()*in.params[1] + in.params[0] })
+	case GlorotUniformInitializer:
+		limit := math.Sqrt(6 / (fanIn + fanOut))
+		m.RandomizeWithRand(limit, -1*limit, random)
+	case GlorotNormalInitializer:
+		stddev := math.Sqrt(2 / (fanIn + fanOut))
+		m.fill(func() float64 { return random.NormFloat64() * stddev })
+	case HeUniformInitializer:
+		limit := math.Sqrt(6 / fanIn)
+		m.RandomizeWithRand(limit, -1*limit, random)
+	case HeNormalInitializer:
+		stddev := math.Sqrt(2 / fanIn)
+		m.fill(func() float64 { return random.NormFloat64() * stddev })
+	case LeCunUniformInitializer:
+		limit := math.Sqrt(3 / fanIn)
+		m.RandomizeWithRand(limit, -1*limit, random)
+	case LeCunNormalInitializer:
+		stddev := math.Sqrt(1 / fanIn)
+		m.fill(func() float64 { return random.NormFloat64() * stddev })
+	case OrthogonalInitializer:
+		m.fill(func() float64 { return random.NormFloat64() })
+		m.orthonormalize()
+		m.Multiply(in.params[0])
+	default:
+		return ErrUnknownInitializer
+	}
+	return nil
+}
+
+// fill sets every value of a Matrix to the result of the given function
+func (m *Matrix) fill(value func() float64) {
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			m.data[i][j] = value()
+		}
+	}
+}
+
+// orthonormalize makes the rows or the columns of a Matrix orthonormal, whichever are fewer
+// It uses the modified Gram Schmidt process
+func (m *Matrix) orthonormalize() {
+	vectors := m
+	if m.rows > m.cols {
+		vectors = m.Transpose()
+	}
+
+	for i := 0; i < vectors.rows; i++ {
+		row := vectors.data[i]
+		for k := 0; k < i; k++ {
+			var dot float64
+			for j := range row {
+				dot = dot + row[j]*vectors.data[k][j]
+			}
+			for j := range row {
+				row[j] = row[j] - dot*vectors.data[k][j]
+			}
+		}
+		var norm float64
+		for j := range row {
+			norm = norm + row[j]*row[j]
+		}
+		norm = math.Sqrt(norm)
+		for j := range row {
+			if norm > 0 {
+				row[j] = row[j] / norm
+			}
+		}
+	}
+
+	if vectors != m {
+		for i := 0; i < m.rows; i++ {
+			for j := 0; j < m.cols; j++ {
+				m.data[i][j] = vectors.data[j][i]
+			}
+		}
+	}
+}
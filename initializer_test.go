@@ -0,0 +1,92 @@
+package gomlp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestInitializerVariances(t *testing.T) {
+	fanIn, fanOut := 300.0, 200.0
+	cases := map[string]struct {
+		initializer Initializer
+		variance    float64
+	}{
+		"glorot uniform": {GlorotUniform(), 2 / (fanIn + fanOut)},
+		"glorot normal":  {GlorotNormal(), 2 / (fanIn + fanOut)},
+		"he uniform":     {HeUniform(), 2 / fanIn},
+		"he normal":      {HeNormal(), 2 / fanIn},
+		"lecun uniform":  {LeCunUniform(), 1 / fanIn},
+		"lecun normal":   {LeCunNormal(), 1 / fanIn},
+		"uniform":        {Uniform(-3, 3), 3},
+		"normal":         {Normal(0, 0.5), 0.25},
+	}
+	for name, tc := range cases {
+		m, _ := NewMatrix(int(fanOut), int(fanIn))
+		if err := tc.initializer.Initialize(m, rand.New(rand.NewSource(10))); err != nil {
+			t.Fatal(err)
+		}
+		var sum, squares float64
+		for _, row := range m.data {
+			for _, x := range row {
+				sum = sum + x
+				squares = squares + x*x
+			}
+		}
+		n := fanIn * fanOut
+		mean := sum / n
+		variance := squares/n - mean*mean
+		if math.Abs(mean) > 0.05*math.Sqrt(tc.variance) || math.Abs(variance-tc.variance) > 0.05*tc.variance {
+			t.Errorf("%s: got mean %v and variance %v, want 0 and %v", name, mean, variance, tc.variance)
+		}
+	}
+}
+
+func TestOrthogonalInitializer(t *testing.T) {
+	for _, shape := range [][2]int{{4, 9}, {9, 4}, {5, 5}} {
+		m, _ := NewMatrix(shape[0], shape[1])
+		if err := Orthogonal(2).Initialize(m, rand.New(rand.NewSource(11))); err != nil {
+			t.Fatal(err)
+		}
+		vectors := m
+		if m.rows > m.cols {
+			vectors = m.Transpose()
+		}
+		for i := 0; i < vectors.rows; i++ {
+			for j := 0; j < vectors.rows; j++ {
+				var dot float64
+				for k := 0; k < vectors.cols; k++ {
+					dot = dot + vectors.data[i][k]*vectors.data[j][k]
+				}
+				want := 0.0
+				if i == j {
+					want = 4
+				}
+				if math.Abs(dot-want) > 1e-9 {
+					t.Errorf("%v: got dot product %v of vectors %d and %d, want %v", shape, dot, i, j, want)
+				}
+			}
+		}
+	}
+}
+
+func TestParseInitializer(t *testing.T) {
+	for _, initializer := range []Initializer{Zeros(), Constant(0.1), Uniform(-1, 2.5), Normal(0, 1e-3), HeNormal(), Orthogonal(1.5)} {
+		parsed, err := ParseInitializer(initializer.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.String() != initializer.String() {
+			t.Errorf("got %s, want %s", parsed, initializer)
+		}
+	}
+	if _, err := ParseInitializer("xavier"); err != ErrUnknownInitializer {
+		t.Errorf("got error %v, want %v", err, ErrUnknownInitializer)
+	}
+	if _, err := ParseInitializer("uniform(1)"); err != ErrInitializerParameters {
+		t.Errorf("got error %v, want %v", err, ErrInitializerParameters)
+	}
+	if _, err := ParseInitializer("constant(1"); err != ErrUnknownInitializer {
+		t.Errorf("got error %v, want %v", err, ErrUnknownInitializer)
+	}
+}
@@ -0,0 +1,509 @@
+package gomlp
+
+import (
+	"math"
+	"sort"
+)
+
+// Names of the calibration methods of int8 quantization
+const (
+	MinMaxCalibrationMethod     = "minmax"
+	PercentileCalibrationMethod = "percentile"
+	EntropyCalibrationMethod    = "entropy"
+)
+
+// QuantizationGranularity decides whether weights share a scale per tensor or have one per output channel
+type QuantizationGranularity int
+
+// Granularities of int8 weight quantization
+const (
+	PerTensor QuantizationGranularity = iota
+	PerChannel
+)
+
+var entropyBins = 2048
+
+var entropyLevels = 128
+
+var entropySmoothing = 0.0001
+
+// MinMaxCalibration returns the calibration covering the full range of the calibration data
+func MinMaxCalibration() Calibration {
+	return Calibration{MinMaxCalibrationMethod, 100}
+}
+
+// PercentileCalibration returns the calibration covering a central percentile of the calibration data
+func PercentileCalibration(percentile float64) Calibration {
+	return Calibration{PercentileCalibrationMethod, percentile}
+}
+
+// EntropyCalibration returns the calibration choosing the range that minimizes the KL divergence between the
+// histogram of the calibration data and its quantized histogram
+func EntropyCalibration() Calibration {
+	return Calibration{EntropyCalibrationMethod, 100}
+}
+
+// Name returns the name of the calibration method
+func (c Calibration) Name() string {
+	return c.name
+}
+
+// Percentile returns the central percentile covered by a percentile calibration
+func (c Calibration) Percentile() float64 {
+	return c.percentile
+}
+
+// calibrate returns the range of values an activation tensor is quantized over
+func (c Calibration) calibrate(values []float64) (float64, float64, error) {
+	if len(values) == 0 {
+		return 0, 0, ErrRowColumnDimension
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	min, max := sorted[0], sorted[len(sorted)-1]
+
+	switch c.name {
+	case MinMaxCalibrationMethod:
+		return min, max, nil
+	case PercentileCalibrationMethod:
+		if c.percentile <= 50 || c.percentile > 100 {
+			return 0, 0, ErrCalibration
+		}
+		high := int(math.Ceil(c.percentile/100*float64(len(sorted)))) - 1
+		low := len(sorted) - 1 - high
+		return sorted[low], sorted[high], nil
+	case EntropyCalibrationMethod:
+		threshold := entropyThreshold(sorted)
+		return math.Max(min, -1*threshold), math.Min(max, threshold), nil
+	default:
+		return 0, 0, ErrCalibration
+	}
+}
+
+// entropyThreshold returns the magnitude beyond which values are clipped so that the KL divergence between
+// the histogram of the magnitudes and the histogram quantized to the int8 levels is the smallest
+func entropyThreshold(values []float64) float64 {
+	var largest float64
+	for _, x := range values {
+		largest = math.Max(largest, math.Abs(x))
+	}
+	if largest == 0 {
+		return 0
+	}
+
+	width := largest / float64(entropyBins)
+	histogram := make([]float64, entropyBins)
+	for _, x := range values {
+		bin := int(math.Abs(x) / width)
+		if bin >= entropyBins {
+			bin = entropyBins - 1
+		}
+		histogram[bin] = histogram[bin] + 1
+	}
+
+	best, bestDivergence := entropyBins, math.Inf(1)
+	for i := entropyLevels; i <= entropyBins; i++ {
+		reference := append([]float64(nil), histogram[:i]...)
+		for _, count := range histogram[i:] {
+			reference[i-1] = reference[i-1] + count
+		}
+
+		// merge the bins into the quantized levels and spread every level over the bins of the reference that
+		// are non empty so the clipped values in the last bin are compared against the level holding it
+		candidate := make([]float64, i)
+		merge := float64(i) / float64(entropyLevels)
+		for level := 0; level < entropyLevels; level++ {
+			start := int(float64(level) * merge)
+			end := int(float64(level+1) * merge)
+			if level == entropyLevels-1 {
+				end = i
+			}
+			var sum, nonZero float64
+			for j := start; j < end; j++ {
+				sum = sum + histogram[j]
+				if reference[j] != 0 {
+					nonZero = nonZero + 1
+				}
+			}
+			for j := start; j < end; j++ {
+				if reference[j] != 0 {
+					candidate[j] = sum / nonZero
+				}
+			}
+		}
+
+		divergence := klDivergence(reference, smoothDistribution(candidate))
+		if divergence < bestDivergence {
+			best, bestDivergence = i, divergence
+		}
+	}
+	return (float64(best) + 0.5) * width
+}
+
+// smoothDistribution returns a histogram with every empty bin given a small share of the total count taken
+// proportionally from the non empty bins so that a level holding only clipped values keeps the divergence finite
+func smoothDistribution(histogram []float64) []float64 {
+	var total, zeros float64
+	for _, count := range histogram {
+		total = total + count
+		if count == 0 {
+			zeros = zeros + 1
+		}
+	}
+	if total == 0 || zeros == 0 {
+		return histogram
+	}
+
+	share := entropySmoothing * total / float64(len(histogram))
+	kept := 1 - share*zeros/total
+	smoothed := make([]float64, len(histogram))
+	for i, count := range histogram {
+		if count == 0 {
+			smoothed[i] = share
+		} else {
+			smoothed[i] = count * kept
+		}
+	}
+	return smoothed
+}
+
+// klDivergence returns the KL divergence between two histograms normalized to distributions
+func klDivergence(p, q []float64) float64 {
+	var pSum, qSum float64
+	for i := range p {
+		pSum = pSum + p[i]
+		qSum = qSum + q[i]
+	}
+	if pSum == 0 || qSum == 0 {
+		return math.Inf(1)
+	}
+
+	var divergence float64
+	for i := range p {
+		if p[i] == 0 {
+			continue
+		}
+		if q[i] == 0 {
+			return math.Inf(1)
+		}
+		pi, qi := p[i]/pSum, q[i]/qSum
+		divergence = divergence + pi*math.Log(pi/qi)
+	}
+	return divergence
+}
+
+// newAsymmetricParameters returns the scale and zero point mapping a range that includes zero onto int8
+func newAsymmetricParameters(min, max float64) QuantizationParameters {
+	min, max = math.Min(min, 0), math.Max(max, 0)
+	scale := (max - min) / 255
+	if scale == 0 {
+		scale = 1
+	}
+	zeroPoint := math.Round(-128 - min/scale)
+	zeroPoint = math.Max(-128, math.Min(127, zeroPoint))
+	return QuantizationParameters{scale, int32(zeroPoint)}
+}
+
+// newSymmetricParameters returns the scale mapping the magnitudes of weights onto int8 with a zero point of zero
+func newSymmetricParameters(weights []float64) QuantizationParameters {
+	var largest float64
+	for _, w := range weights {
+		largest = math.Max(largest, math.Abs(w))
+	}
+	scale := largest / 127
+	if scale == 0 {
+		scale = 1
+	}
+	return QuantizationParameters{scale, 0}
+}
+
+// Quantize returns the int8 value closest to a real number
+func (p QuantizationParameters) Quantize(x float64) int8 {
+	q := math.Round(x/p.Scale) + float64(p.ZeroPoint)
+	return int8(math.Max(-128, math.Min(127, q)))
+}
+
+// Dequantize returns the real number of an int8 value
+func (p QuantizationParameters) Dequantize(q int8) float64 {
+	return p.Scale * float64(int32(q)-p.ZeroPoint)
+}
+
+// quantizeMultiplier returns a positive real multiplier as an int32 in [2^30, 2^31) and a power of two
+func quantizeMultiplier(multiplier float64) (int32, int) {
+	if multiplier <= 0 {
+		return 0, 0
+	}
+	fraction, shift := math.Frexp(multiplier)
+	q := int64(math.Round(fraction * (1 << 31)))
+	if q == 1<<31 {
+		q = q / 2
+		shift = shift + 1
+	}
+	return int32(q), shift
+}
+
+// requantize returns the int32 accumulator scaled by an int32 multiplier and a power of two with rounding
+func requantize(acc, multiplier int32, shift int) int32 {
+	product := int64(acc) * int64(multiplier)
+	total := 31 - shift
+	var result int64
+	if total > 0 {
+		if total > 62 {
+			return 0
+		}
+		result = (product + int64(1)<<uint(total-1)) >> uint(total)
+	} else {
+		result = product << uint(-1*total)
+	}
+	if result > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	if result < math.MinInt32 {
+		return math.MinInt32
+	}
+	return int32(result)
+}
+
+// NewInt8Classifier return a new pointer to a trained Classifier quantized to int8 after calibrating the ranges
+// of its activations on a calibration dataset
+// Weights are quantized symmetrically and activations asymmetrically with a zero point
+func NewInt8Classifier(mlp *Classifier, calibration [][]float64, method Calibration, granularity QuantizationGranularity) (*Int8Classifier, error) {
+	qn, err := newInt8Network(mlp.network, calibration, method, granularity)
+	if err != nil {
+		return &Int8Classifier{}, err
+	}
+
+	classes := append([]float64(nil), mlp.Classes...)
+
+	return &Int8Classifier{
+		qn,
+		classes,
+	}, nil
+}
+
+// newInt8Network return a new pointer to the network quantized to int8
+func newInt8Network(nn *network, calibration [][]float64, method Calibration, granularity QuantizationGranularity) (*int8Network, error) {
+	if granularity != PerTensor && granularity != PerChannel {
+		return &int8Network{}, ErrCalibration
+	}
+	indices := make([]int, len(calibration))
+	for i := range indices {
+		indices[i] = i
+	}
+	inputs, err := ConvertFromRowsToMatrix(calibration, indices)
+	if err != nil {
+		return &int8Network{}, err
+	}
+	zs, activations, err := nn.feedForward(inputs)
+	if err != nil {
+		return &int8Network{}, err
+	}
+
+	calibrate := func(m *Matrix) (QuantizationParameters, error) {
+		var values []float64
+		for _, row := range m.data {
+			values = append(values, row...)
+		}
+		min, max, err := method.calibrate(values)
+		if err != nil {
+			return QuantizationParameters{}, err
+		}
+		return newAsymmetricParameters(min, max), nil
+	}
+
+	input, err := calibrate(activations[0])
+	if err != nil {
+		return &int8Network{}, err
+	}
+	layers := make([]int8Layer, len(nn.layers))
+	for i, layer := range nn.layers {
+		if layer.activationFunc.function == nil && i < len(nn.layers)-1 {
+			return &int8Network{}, ErrInt8Unsupported
+		}
+		sum, err := calibrate(zs[i])
+		if err != nil {
+			return &int8Network{}, err
+		}
+		output := sum
+		if layer.activationFunc.function != nil {
+			output, err = calibrate(activations[i+1])
+			if err != nil {
+				return &int8Network{}, err
+			}
+		}
+
+		q := int8Layer{
+			weights:        make([][]int8, layer.outputNodes),
+			bias:           make([]int32, layer.outputNodes),
+			weightParams:   make([]QuantizationParameters, layer.outputNodes),
+			multipliers:    make([]int32, layer.outputNodes),
+			shifts:         make([]int, layer.outputNodes),
+			input:          input,
+			sum:            sum,
+			output:         output,
+			activationFunc: layer.activationFunc,
+		}
+		var all []float64
+		for _, row := range layer.weights.data {
+			all = append(all, row...)
+		}
+		tensor := newSymmetricParameters(all)
+		for r, row := range layer.weights.data {
+			q.weightParams[r] = tensor
+			if granularity == PerChannel {
+				q.weightParams[r] = newSymmetricParameters(row)
+			}
+			q.weights[r] = make([]int8, len(row))
+			for c, w := range row {
+				q.weights[r][c] = q.weightParams[r].Quantize(w)
+			}
+			accumulatorScale := input.Scale * q.weightParams[r].Scale
+			bias := math.Round(layer.bias.data[r][0] / accumulatorScale)
+			q.bias[r] = int32(math.Max(math.MinInt32, math.Min(math.MaxInt32, bias)))
+			q.multipliers[r], q.shifts[r] = quantizeMultiplier(accumulatorScale / sum.Scale)
+		}
+		if layer.activationFunc.function != nil {
+			q.table = make([]int8, 256)
+			for z := -128; z < 128; z++ {
+				q.table[z+128] = output.Quantize(layer.activationFunc.function(sum.Dequantize(int8(z))))
+			}
+		}
+		layers[i] = q
+		input = output
+	}
+
+	return &int8Network{
+		layers,
+	}, nil
+}
+
+// QuantizeInput returns the int8 values of an input row
+func (qn *int8Network) QuantizeInput(inputArr []float64) []int8 {
+	inputs := make([]int8, len(inputArr))
+	for i, x := range inputArr {
+		inputs[i] = qn.layers[0].input.Quantize(x)
+	}
+	return inputs
+}
+
+// QuantizedWeights returns the int8 weights and int32 biases of a layer
+func (qn *int8Network) QuantizedWeights(layer int) ([][]int8, []int32, error) {
+	if layer < 0 || layer >= len(qn.layers) {
+		return [][]int8{}, []int32{}, ErrLayerIndex
+	}
+	weights := make([][]int8, len(qn.layers[layer].weights))
+	for r, row := range qn.layers[layer].weights {
+		weights[r] = append([]int8(nil), row...)
+	}
+	return weights, append([]int32(nil), qn.layers[layer].bias...), nil
+}
+
+// Parameters returns the scales and zero points of the inputs, the weights of every output channel, the sums
+// and the outputs of a layer
+func (qn *int8Network) Parameters(layer int) (QuantizationParameters, []QuantizationParameters, QuantizationParameters, QuantizationParameters, error) {
+	if layer < 0 || layer >= len(qn.layers) {
+		return QuantizationParameters{}, []QuantizationParameters{}, QuantizationParameters{}, QuantizationParameters{}, ErrLayerIndex
+	}
+	l := qn.layers[layer]
+	return l.input, append([]QuantizationParameters(nil), l.weightParams...), l.sum, l.output, nil
+}
+
+// ForwardLayers returns the int32 accumulators and the int8 outputs of every layer using integer arithmetic only
+// Accumulators are requantized to the int8 sums which index the activation table of the layer
+// The outputs start with the quantized inputs and so hold one slice more than the accumulators
+func (qn *int8Network) ForwardLayers(inputArr []float64) ([][]int32, [][]int8, error) {
+	activations := [][]int8{qn.QuantizeInput(inputArr)}
+	var accumulators [][]int32
+	for _, layer := range qn.layers {
+		inputs := activations[len(activations)-1]
+		if len(layer.weights) == 0 || len(inputs) != len(layer.weights[0]) {
+			return accumulators, activations, ErrRowColumnDimension
+		}
+
+		sums := make([]int32, len(layer.weights))
+		outputs := make([]int8, len(layer.weights))
+		for r, row := range layer.weights {
+			acc := layer.bias[r]
+			for c, weight := range row {
+				acc = acc + int32(weight)*(int32(inputs[c])-layer.input.ZeroPoint)
+			}
+			sums[r] = acc
+
+			z := layer.sum.ZeroPoint + requantize(acc, layer.multipliers[r], layer.shifts[r])
+			if z > 127 {
+				z = 127
+			}
+			if z < -128 {
+				z = -128
+			}
+			outputs[r] = int8(z)
+			if layer.table != nil {
+				outputs[r] = layer.table[z+128]
+			}
+		}
+		accumulators = append(accumulators, sums)
+		activations = append(activations, outputs)
+	}
+	return accumulators, activations, nil
+}
+
+// Outputs returns the outputs of the network for a single input row converted back to real numbers
+// An output layer without a scalar activation such as softmax is applied to the dequantized sums
+func (qn *int8Network) Outputs(inputArr []float64) ([]float64, error) {
+	_, activations, err := qn.ForwardLayers(inputArr)
+	if err != nil {
+		return []float64{}, err
+	}
+	last := qn.layers[len(qn.layers)-1]
+	raw := activations[len(activations)-1]
+	outputs, _ := NewMatrix(len(raw), 1)
+	for i, value := range raw {
+		outputs.data[i][0] = last.output.Dequantize(value)
+	}
+	if last.table == nil {
+		outputs = last.activationFunc.apply(outputs)
+	}
+	return outputs.ConvertFromMatrixToArray1D(), nil
+}
+
+// Predict return the predicted class index of the int8 network in the same way as Classifier.Predict
+func (qc *Int8Classifier) Predict(inputArr []float64) (int, error) {
+	outputs, err := qc.Outputs(inputArr)
+	if err != nil {
+		return 0, err
+	}
+	return classifyOutputs(outputs), nil
+}
+
+// Score return the accuracy of the int8 network in the same way as Classifier.Score
+func (qc *Int8Classifier) Score(data [][]float64, target [][]float64) (float64, error) {
+	return scoreClassifier(qc.Predict, data, target)
+}
+
+// Agreement return the share of rows where the int8 network predicts the same class as a Classifier
+func (qc *Int8Classifier) Agreement(mlp *Classifier, data [][]float64) (float64, error) {
+	return agreement(qc.Predict, mlp.Predict, data)
+}
+
+// Compare returns the accuracy of the int8 network and of the float Classifier it was quantized from
+func (qc *Int8Classifier) Compare(mlp *Classifier, data [][]float64, target [][]float64) (QuantizationReport, error) {
+	floatAccuracy, err := mlp.Score(data, target)
+	if err != nil {
+		return QuantizationReport{}, err
+	}
+	quantizedAccuracy, err := qc.Score(data, target)
+	if err != nil {
+		return QuantizationReport{}, err
+	}
+	agreed, err := qc.Agreement(mlp, data)
+	if err != nil {
+		return QuantizationReport{}, err
+	}
+
+	return QuantizationReport{
+		FloatAccuracy:     floatAccuracy,
+		QuantizedAccuracy: quantizedAccuracy,
+		AccuracyDrop:      floatAccuracy - quantizedAccuracy,
+		Agreement:         agreed,
+	}, nil
+}
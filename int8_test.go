@@ -0,0 +1,119 @@
+package gomlp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRequantizeMatchesRealMultipliers(t *testing.T) {
+	random := rand.New(rand.NewSource(8))
+	for i := 0; i < 10000; i++ {
+		multiplier := math.Ldexp(random.Float64()+0.01, -1*random.Intn(20))
+		acc := int32(random.Int63n(1<<24) - 1<<23)
+		q, shift := quantizeMultiplier(multiplier)
+		if q < 1<<30 {
+			t.Fatalf("%v: got multiplier %d below 2^30", multiplier, q)
+		}
+		want := math.Round(float64(acc) * multiplier)
+		if got := requantize(acc, q, shift); math.Abs(float64(got)-want) > 1 {
+			t.Fatalf("%d times %v: got %d, want %v", acc, multiplier, got, want)
+		}
+	}
+
+	q, shift := quantizeMultiplier(math.Nextafter(1, 0))
+	if q != 1<<30 || shift != 1 {
+		t.Errorf("got %d and %d, want 2^30 and 1", q, shift)
+	}
+	q, shift = quantizeMultiplier(1 << 10)
+	if got := requantize(1<<30, q, shift); got != math.MaxInt32 {
+		t.Errorf("got %d, want the largest int32", got)
+	}
+	if got := requantize(-1<<30, q, shift); got != math.MinInt32 {
+		t.Errorf("got %d, want the smallest int32", got)
+	}
+}
+
+func TestQuantizationParameters(t *testing.T) {
+	asymmetric := newAsymmetricParameters(-1, 3)
+	if got := asymmetric.Dequantize(asymmetric.Quantize(0)); got != 0 {
+		t.Errorf("got %v for zero", got)
+	}
+	if asymmetric.Quantize(-1) != -128 || asymmetric.Quantize(3) != 127 || asymmetric.Quantize(100) != 127 {
+		t.Errorf("got %d and %d for the ends of the range", asymmetric.Quantize(-1), asymmetric.Quantize(3))
+	}
+	// ranges not including zero are widened to include it
+	if positive := newAsymmetricParameters(2, 4); positive.ZeroPoint != -128 {
+		t.Errorf("got zero point %d, want -128", positive.ZeroPoint)
+	}
+
+	symmetric := newSymmetricParameters([]float64{0.5, -2, 1})
+	if symmetric.ZeroPoint != 0 || symmetric.Quantize(-2) != -127 || symmetric.Quantize(2) != 127 {
+		t.Errorf("got %+v", symmetric)
+	}
+	if zero := newSymmetricParameters([]float64{0, 0}); zero.Scale != 1 {
+		t.Errorf("got scale %v for zero weights", zero.Scale)
+	}
+}
+
+func TestCalibrationMethods(t *testing.T) {
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	min, max, err := MinMaxCalibration().calibrate(values)
+	if err != nil || min != 1 || max != 1000 {
+		t.Errorf("min max: got [%v, %v] with error %v", min, max, err)
+	}
+	min, max, err = PercentileCalibration(99).calibrate(values)
+	if err != nil || min != 11 || max != 990 {
+		t.Errorf("percentile: got [%v, %v] with error %v", min, max, err)
+	}
+	if _, _, err = PercentileCalibration(40).calibrate(values); err != ErrCalibration {
+		t.Errorf("got error %v, want %v", err, ErrCalibration)
+	}
+	if _, _, err = MinMaxCalibration().calibrate(nil); err != ErrRowColumnDimension {
+		t.Errorf("got error %v, want %v", err, ErrRowColumnDimension)
+	}
+
+	// a few large outliers are clipped while the bulk of a normal distribution is kept
+	random := rand.New(rand.NewSource(9))
+	normal := make([]float64, 20000)
+	for i := range normal {
+		normal[i] = random.NormFloat64()
+	}
+	normal = append(normal, 100, -100)
+	min, max, err = EntropyCalibration().calibrate(normal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max < 2 || max > 10 || min > -2 || min < -10 {
+		t.Errorf("entropy: got [%v, %v]", min, max)
+	}
+
+	if got := klDivergence([]float64{1, 2, 3}, []float64{2, 4, 6}); got != 0 {
+		t.Errorf("got divergence %v between equal distributions", got)
+	}
+	if got := klDivergence([]float64{1, 2}, []float64{1, 0}); !math.IsInf(got, 1) {
+		t.Errorf("got divergence %v, want infinity", got)
+	}
+}
+
+func TestInt8ClassifierAgrees(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 3}, 30)
+	for _, method := range []Calibration{MinMaxCalibration(), PercentileCalibration(99.9), EntropyCalibration()} {
+		for _, granularity := range []QuantizationGranularity{PerTensor, PerChannel} {
+			qc, err := NewInt8Classifier(mlp, data[:100], method, granularity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := qc.Compare(mlp, data, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Agreement < 0.9 {
+				t.Errorf("%s granularity %d: got %+v", method.Name(), granularity, report)
+			}
+		}
+	}
+}
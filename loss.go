@@ -0,0 +1,133 @@
+package gomlp
+
+import "math"
+
+// Names of the loss functions
+const (
+	MeanSquaredError        = "mse"
+	MeanAbsoluteError       = "mae"
+	Huber                   = "huber"
+	BinaryCrossEntropy      = "binary_crossentropy"
+	CategoricalCrossEntropy = "categorical_crossentropy"
+)
+
+var lossEpsilon = 1e-12
+
+var huberDelta = 1.0
+
+var meanSquaredError = LossFunction{
+	name: MeanSquaredError,
+	function: func(y, t float64) float64 {
+		return 0.5 * (y - t) * (y - t)
+	},
+	dfunction: func(y, t float64) float64 {
+		return y - t
+	},
+}
+
+var meanAbsoluteError = LossFunction{
+	name: MeanAbsoluteError,
+	function: func(y, t float64) float64 {
+		return math.Abs(y - t)
+	},
+	dfunction: func(y, t float64) float64 {
+		if y > t {
+			return 1
+		} else if y < t {
+			return -1
+		}
+		return 0
+	},
+}
+
+var huber = LossFunction{
+	name: Huber,
+	function: func(y, t float64) float64 {
+		diff := math.Abs(y - t)
+		if diff <= huberDelta {
+			return 0.5 * diff * diff
+		}
+		return huberDelta * (diff - 0.5*huberDelta)
+	},
+	dfunction: func(y, t float64) float64 {
+		return math.Max(-1*huberDelta, math.Min(huberDelta, y-t))
+	},
+}
+
+var binaryCrossEntropy = LossFunction{
+	name: BinaryCrossEntropy,
+	function: func(y, t float64) float64 {
+		y = math.Min(math.Max(y, lossEpsilon), 1-lossEpsilon)
+		return -1 * (t*math.Log(y) + (1-t)*math.Log(1-y))
+	},
+	dfunction: func(y, t float64) float64 {
+		y = math.Min(math.Max(y, lossEpsilon), 1-lossEpsilon)
+		return (y - t) / (y * (1 - y))
+	},
+}
+
+var categoricalCrossEntropy = LossFunction{
+	name: CategoricalCrossEntropy,
+	function: func(y, t float64) float64 {
+		return -1 * t * math.Log(math.Max(y, lossEpsilon))
+	},
+	dfunction: func(y, t float64) float64 {
+		return -1 * t / math.Max(y, lossEpsilon)
+	},
+}
+
+var lossFunctions = map[string]LossFunction{
+	MeanSquaredError:        meanSquaredError,
+	MeanAbsoluteError:       meanAbsoluteError,
+	Huber:                   huber,
+	BinaryCrossEntropy:      binaryCrossEntropy,
+	CategoricalCrossEntropy: categoricalCrossEntropy,
+}
+
+// GetLoss returns the loss function with the given name
+func GetLoss(name string) (LossFunction, error) {
+	lossFunc, ok := lossFunctions[name]
+	if !ok {
+		return LossFunction{}, ErrUnknownLoss
+	}
+	return lossFunc, nil
+}
+
+// Name returns the name of the loss function
+func (l LossFunction) Name() string {
+	return l.name
+}
+
+// loss returns the loss summed over the outputs and averaged over the columns of the output Matrix
+func (l LossFunction) loss(output, target *Matrix) (float64, error) {
+	if output.rows != target.rows || output.cols != target.cols {
+		return 0, ErrRowColumnDimension
+	}
+
+	var sum float64
+	for i := 0; i < output.rows; i++ {
+		for j := 0; j < output.cols; j++ {
+			sum = sum + l.function(output.data[i][j], target.data[i][j])
+		}
+	}
+	return sum / float64(output.cols), nil
+}
+
+// outputGradient returns the gradient of the loss with respect to the weighted inputs of the output layer
+// Softmax with categorical cross entropy and sigmoid with binary cross entropy reduce to the difference of output and target
+func (l LossFunction) outputGradient(z, output, target *Matrix, activationFunc ActivationFunction) (*Matrix, error) {
+	if (l.name == CategoricalCrossEntropy && activationFunc.name == Softmax) || (l.name == BinaryCrossEntropy && activationFunc.name == Sigmoid) {
+		return Subtract(output, target)
+	}
+
+	if output.rows != target.rows || output.cols != target.cols {
+		return &Matrix{}, ErrRowColumnDimension
+	}
+	gradient, _ := NewMatrix(output.rows, output.cols)
+	for i := 0; i < output.rows; i++ {
+		for j := 0; j < output.cols; j++ {
+			gradient.data[i][j] = l.dfunction(output.data[i][j], target.data[i][j])
+		}
+	}
+	return activationFunc.backward(z, output, gradient)
+}
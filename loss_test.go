@@ -0,0 +1,37 @@
+package gomlp
+
+import "testing"
+
+func TestCrossEntropyGradients(t *testing.T) {
+	matchesNumericalGradients(t, []string{Sigmoid, Tanh, Softmax}, CategoricalCrossEntropy, [][]float64{{1, 0}, {0, 1}})
+	matchesNumericalGradients(t, []string{Tanh, Sigmoid, Sigmoid}, BinaryCrossEntropy, [][]float64{{1, 0}, {0, 1}})
+}
+
+func TestTrainRejectsMoreClassesThanOutputs(t *testing.T) {
+	data := [][]float64{{0, 1}, {1, 0}, {1, 1}}
+	target := [][]float64{{1}, {2}, {3}}
+	mlp, err := NewClassifierWithLayers([]int{2, 4, 3}, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.SetActivations([]string{Sigmoid, Softmax}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.SetLoss(CategoricalCrossEntropy); err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.Train(data, target, 1); err != ErrTargetDimension {
+		t.Errorf("got error %v, want %v", err, ErrTargetDimension)
+	}
+
+	wide, err := NewClassifierWithLayers([]int{2, 4, 4}, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wide.Train(data, target, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(wide.Classes) != 4 {
+		t.Errorf("got classes %v, want 0 to 3", wide.Classes)
+	}
+}
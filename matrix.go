@@ -75,6 +75,21 @@ func Add(a, b *Matrix) (*Matrix, error) {
 	return m, nil
 }
 
+// AddColumnVector adds a single column Matrix to every column of a Matrix
+func AddColumnVector(m, v *Matrix) (*Matrix, error) {
+	if m.rows != v.rows || v.cols != 1 {
+		return &Matrix{}, ErrRowColumnDimension
+	}
+
+	sum, _ := NewMatrix(m.rows, m.cols)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			sum.data[i][j] = m.data[i][j] + v.data[i][0]
+		}
+	}
+	return sum, nil
+}
+
 // Multiply is used to perform matrix multiplication
 func Multiply(a, b *Matrix) (*Matrix, error) {
 	if a.cols != b.rows {
@@ -107,6 +122,27 @@ func ConvertFromArrayToMatrix1D(data []float64) (*Matrix, error) {
 	return m, nil
 }
 
+// ConvertFromRowsToMatrix converts the selected rows of an Array object to a Matrix with a column for every row
+func ConvertFromRowsToMatrix(data [][]float64, indices []int) (*Matrix, error) {
+	if len(indices) == 0 {
+		return &Matrix{}, ErrRowColumnRange
+	}
+
+	m, err := NewMatrix(len(data[indices[0]]), len(indices))
+	if err != nil {
+		return m, err
+	}
+	for j, index := range indices {
+		if len(data[index]) != m.rows {
+			return &Matrix{}, ErrRowColumnDimension
+		}
+		for i, element := range data[index] {
+			m.data[i][j] = element
+		}
+	}
+	return m, nil
+}
+
 // ConvertFromArray2DToMatrix converts an Array object to a Matrix
 func ConvertFromArray2DToMatrix(data [][]float64) (*Matrix, error) {
 	m, err := NewMatrix(len(data), len(data[0]))
@@ -156,6 +192,16 @@ func (m *Matrix) Randomize(max, min float64) {
 	}
 }
 
+// RandomizeWithRand is used to initialize all the values to a random value drawn from the given generator
+func (m *Matrix) RandomizeWithRand(max, min float64, random *rand.Rand) {
+	diff := max - min
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			m.data[i][j] = diff*random.Float64() + min
+		}
+	}
+}
+
 // Copy creates a copy of a Matrix
 func (m *Matrix) Copy() *Matrix {
 	m1, _ := NewMatrix(m.rows, m.cols)
@@ -194,6 +240,17 @@ func (m *Matrix) Multiply(n float64) {
 	}
 }
 
+// SumColumns adds all the columns of a Matrix into a single column
+func (m *Matrix) SumColumns() *Matrix {
+	sum, _ := NewMatrix(m.rows, 1)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			sum.data[i][0] = sum.data[i][0] + m.data[i][j]
+		}
+	}
+	return sum
+}
+
 // Transpose a Matrix
 func (m *Matrix) Transpose() *Matrix {
 	m1, _ := NewMatrix(m.cols, m.rows)
@@ -0,0 +1,438 @@
+package gomlp
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ModelFormatVersion is the version of the model file format written by Save
+// Version 0 is the layout of separate CSV files written by earlier releases and is migrated on load
+const ModelFormatVersion = 1
+
+// Kinds of models stored in a model file
+const (
+	ClassifierModel = "classifier"
+	RegressorModel  = "regressor"
+)
+
+var modelFormat = "gomlp"
+
+var modelFile = "model.json"
+
+var activationsFile = "activations.csv"
+
+var initializersFile = "initializers.csv"
+
+var optimizerFile = "optimizer.json"
+
+var classesFile = "classes.csv"
+
+// modelMigrations upgrade a decoded model file from the version it is indexed by to the next version
+var modelMigrations = map[int]func(map[string]json.RawMessage) error{}
+
+// savedModel is the Data Structure to hold a model file
+type savedModel struct {
+	Format        string                 `json:"format"`
+	Version       int                    `json:"version"`
+	Kind          string                 `json:"kind"`
+	LayerSizes    []int                  `json:"layer_sizes"`
+	Activations   []string               `json:"activations"`
+	Initializers  []string               `json:"initializers"`
+	Loss          string                 `json:"loss"`
+	BatchSize     int                    `json:"batch_size"`
+	Classes       []float64              `json:"classes,omitempty"`
+	Preprocessing *attachedPreprocessing `json:"preprocessing,omitempty"`
+	Optimizer     *OptimizerState        `json:"optimizer,omitempty"`
+	Quantization  *savedQuantization     `json:"quantization,omitempty"`
+	Pruned        bool                   `json:"pruned,omitempty"`
+	Layers        []savedLayer           `json:"layers"`
+}
+
+// savedLayer is the Data Structure to hold the weights and bias of a layer in a model file
+type savedLayer struct {
+	Weights [][]float64 `json:"weights"`
+	Bias    []float64   `json:"bias"`
+}
+
+// attachedPreprocessing is the Data Structure to hold the preprocessing attached to a model
+type attachedPreprocessing struct {
+	Kind string    `json:"kind"`
+	Mean []float64 `json:"mean,omitempty"`
+	Dev  []float64 `json:"dev,omitempty"`
+	Min  []float64 `json:"min,omitempty"`
+	Max  []float64 `json:"max,omitempty"`
+	High float64   `json:"high"`
+	Low  float64   `json:"low"`
+}
+
+// document returns the model file describing the network
+func (nn *network) document(kind string) *savedModel {
+	layers := make([]savedLayer, len(nn.layers))
+	for i, layer := range nn.layers {
+		layers[i] = savedLayer{
+			layer.weights.ConvertFromMatrixToArray2D(),
+			layer.bias.ConvertFromMatrixToArray1D(),
+		}
+	}
+	optimizer := nn.optimizer.State()
+
+	return &savedModel{
+		Format:        modelFormat,
+		Version:       ModelFormatVersion,
+		Kind:          kind,
+		LayerSizes:    nn.LayerSizes(),
+		Activations:   nn.Activations(),
+		Initializers:  nn.Initializers(),
+		Loss:          nn.lossFunc.name,
+		BatchSize:     nn.batchSize,
+		Preprocessing: nn.preprocessing,
+		Optimizer:     &optimizer,
+		Quantization:  nn.quantization.saved(),
+		Pruned:        nn.masks != nil,
+		Layers:        layers,
+	}
+}
+
+// writeDocument writes a model file as JSON to a writer
+func writeDocument(w io.Writer, doc *savedModel) error {
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// writeDocumentToDir writes a model file into a directory creating it if needed
+func writeDocumentToDir(path string, doc *savedModel) error {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(path, modelFile), func(w io.Writer) error {
+		return writeDocument(w, doc)
+	})
+}
+
+// readDocument reads a model file of any supported version from a reader
+// Version 0 tar archives of separate CSV files are migrated to the current version
+func readDocument(r io.Reader) (*savedModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &savedModel{}, err
+	}
+
+	if len(data) > 262 && string(data[257:262]) == "ustar" {
+		files, err := readTarFiles(bytes.NewReader(data))
+		if err != nil {
+			return &savedModel{}, err
+		}
+		return legacyDocument(func(name string) ([]byte, error) {
+			file, ok := files[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return file, nil
+		})
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return &savedModel{}, err
+	}
+
+	var format string
+	var version int
+	err = json.Unmarshal(fields["format"], &format)
+	if err != nil || format != modelFormat {
+		return &savedModel{}, ErrModelFormat
+	}
+	err = json.Unmarshal(fields["version"], &version)
+	if err != nil || version < 1 || version > ModelFormatVersion {
+		return &savedModel{}, ErrModelVersion
+	}
+
+	for ; version < ModelFormatVersion; version++ {
+		migrate, ok := modelMigrations[version]
+		if !ok {
+			return &savedModel{}, ErrModelVersion
+		}
+		err = migrate(fields)
+		if err != nil {
+			return &savedModel{}, err
+		}
+	}
+	fields["version"], _ = json.Marshal(version)
+
+	migrated, err := json.Marshal(fields)
+	if err != nil {
+		return &savedModel{}, err
+	}
+	var doc savedModel
+	err = json.Unmarshal(migrated, &doc)
+	if err != nil {
+		return &savedModel{}, err
+	}
+	return &doc, nil
+}
+
+// readDocumentFromDir reads the model file of a directory or migrates the separate CSV files of earlier releases
+func readDocumentFromDir(path string) (*savedModel, error) {
+	file, err := os.Open(filepath.Join(path, modelFile))
+	if err == nil {
+		defer file.Close()
+		return readDocument(bufio.NewReader(file))
+	}
+	if !os.IsNotExist(err) {
+		return &savedModel{}, err
+	}
+
+	return legacyDocument(func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(path, name))
+	})
+}
+
+// readTarFiles reads every file of a tar archive into memory
+func readTarFiles(r io.Reader) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return files, err
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return files, err
+		}
+		files[header.Name] = data
+	}
+	return files, nil
+}
+
+// legacyDocument migrates the separate CSV files of earlier releases into a model file
+// Files missing from the oldest releases fall back to a single hidden sigmoid layer and the default optimizer
+// The kind of model was not recorded and is left empty so either model can load it
+func legacyDocument(read func(string) ([]byte, error)) (*savedModel, error) {
+	identity := func(input string) string { return input }
+
+	var activations []string
+	data, err := read(activationsFile)
+	if err == nil {
+		activations, err = readNames(bytes.NewReader(data))
+		if err != nil {
+			return &savedModel{}, err
+		}
+	} else if os.IsNotExist(err) {
+		activations = []string{Sigmoid, Sigmoid}
+	} else {
+		return &savedModel{}, err
+	}
+
+	doc := &savedModel{
+		Format:      modelFormat,
+		Version:     ModelFormatVersion,
+		Activations: activations,
+		Loss:        MeanSquaredError,
+		BatchSize:   1,
+	}
+
+	weightsFiles, biasFiles := layerFileNames(len(activations))
+	for i := range weightsFiles {
+		data, err = read(weightsFiles[i])
+		if err != nil {
+			return &savedModel{}, err
+		}
+		weights, err := readData(bytes.NewReader(data), identity)
+		if err != nil {
+			return &savedModel{}, err
+		}
+
+		data, err = read(biasFiles[i])
+		if err != nil {
+			return &savedModel{}, err
+		}
+		biasArr, err := readData(bytes.NewReader(data), identity)
+		if err != nil {
+			return &savedModel{}, err
+		}
+		bias := make([]float64, len(biasArr))
+		for j, row := range biasArr {
+			if len(row) != 1 {
+				return &savedModel{}, ErrRowColumnDimension
+			}
+			bias[j] = row[0]
+		}
+		doc.Layers = append(doc.Layers, savedLayer{weights, bias})
+	}
+
+	data, err = read(initializersFile)
+	if err == nil {
+		doc.Initializers, err = readNames(bytes.NewReader(data))
+		if err != nil {
+			return &savedModel{}, err
+		}
+	} else if !os.IsNotExist(err) {
+		return &savedModel{}, err
+	}
+
+	data, err = read(optimizerFile)
+	if err == nil {
+		var state OptimizerState
+		err = json.Unmarshal(data, &state)
+		if err != nil {
+			return &savedModel{}, err
+		}
+		doc.Optimizer = &state
+	} else if !os.IsNotExist(err) {
+		return &savedModel{}, err
+	}
+
+	data, err = read(classesFile)
+	if err == nil {
+		classes, err := readData(bytes.NewReader(data), identity)
+		if err != nil {
+			return &savedModel{}, err
+		}
+		if len(classes) > 0 {
+			doc.Classes = classes[0]
+		}
+	} else if !os.IsNotExist(err) {
+		return &savedModel{}, err
+	}
+	return doc, nil
+}
+
+// newNetworkFromDocument return a new pointer to a network described by a model file
+// Optimizers unknown to this package are replaced by the default so the model can still be used for inference
+func newNetworkFromDocument(doc *savedModel) (*network, error) {
+	layers := make([]*Layer, len(doc.Layers))
+	for i, saved := range doc.Layers {
+		weights, err := ConvertFromArray2DToMatrix(saved.Weights)
+		if err != nil {
+			return &network{}, err
+		}
+		bias, err := ConvertFromArrayToMatrix1D(saved.Bias)
+		if err != nil {
+			return &network{}, err
+		}
+		layers[i], err = newLayerFromMatrices(weights, bias)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+
+	nn, err := newNetworkFromLayers(layers)
+	if err != nil {
+		return &network{}, err
+	}
+	if doc.LayerSizes != nil {
+		if len(doc.LayerSizes) != len(nn.layerSizes) {
+			return &network{}, ErrLayerDimension
+		}
+		for i, size := range doc.LayerSizes {
+			if size != nn.layerSizes[i] {
+				return &network{}, ErrLayerDimension
+			}
+		}
+	}
+
+	err = nn.SetActivations(doc.Activations)
+	if err != nil {
+		return &network{}, err
+	}
+	if doc.Initializers != nil {
+		err = nn.setInitializers(doc.Initializers)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+	err = nn.SetLoss(doc.Loss)
+	if err != nil {
+		return &network{}, err
+	}
+	err = nn.SetBatchSize(doc.BatchSize)
+	if err != nil {
+		return &network{}, err
+	}
+	if doc.Optimizer != nil {
+		optimizer, err := NewOptimizerFromState(*doc.Optimizer)
+		if err != nil && err != ErrUnknownOptimizer {
+			return &network{}, err
+		}
+		if err == nil {
+			nn.optimizer = optimizer
+		}
+	}
+	if doc.Preprocessing != nil {
+		err = nn.setPreprocessing(doc.Preprocessing)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+	if doc.Quantization != nil {
+		nn.quantization, err = newFixedPointConfigFromSaved(doc.Quantization)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+	if doc.Pruned {
+		nn.masksFromZeros()
+	}
+	return nn, nil
+}
+
+// layerFileNames returns the CSV file names used by earlier releases to store the weights and biases of every layer
+func layerFileNames(layers int) ([]string, []string) {
+	if layers == 2 {
+		return []string{"weights_input_hidden.csv", "weights_hidden_output.csv"}, []string{"bias_hidden.csv", "bias_output.csv"}
+	}
+
+	weightsFiles := make([]string, layers)
+	biasFiles := make([]string, layers)
+	for i := 0; i < layers; i++ {
+		weightsFiles[i] = fmt.Sprintf("weights_layer_%d.csv", i)
+		biasFiles[i] = fmt.Sprintf("bias_layer_%d.csv", i)
+	}
+	return weightsFiles, biasFiles
+}
+
+// readLayers reads a weights and a bias CSV file for every layer
+func readLayers(weightsFiles, biasFiles []string, stringHandler func(string) string) ([]*Layer, error) {
+	if len(weightsFiles) != len(biasFiles) {
+		return []*Layer{}, ErrLayerFiles
+	}
+
+	layers := make([]*Layer, len(weightsFiles))
+	for i := range weightsFiles {
+		weightsArr, err := ReadData(weightsFiles[i], stringHandler)
+		if err != nil {
+			return layers, err
+		}
+		weights, err := ConvertFromArray2DToMatrix(weightsArr)
+		if err != nil {
+			return layers, err
+		}
+
+		biasArr, err := ReadData(biasFiles[i], stringHandler)
+		if err != nil {
+			return layers, err
+		}
+		bias, err := ConvertFromArray2DToMatrix(biasArr)
+		if err != nil {
+			return layers, err
+		}
+
+		layers[i], err = newLayerFromMatrices(weights, bias)
+		if err != nil {
+			return layers, err
+		}
+	}
+	return layers, nil
+}
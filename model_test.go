@@ -0,0 +1,180 @@
+package gomlp
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 5, 3}, 3)
+	var buffer bytes.Buffer
+	if err := mlp.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadClassifier(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameNetworks(t, loaded.network, mlp.network)
+	if !reflect.DeepEqual(loaded.Classes, mlp.Classes) {
+		t.Errorf("got classes %v, want %v", loaded.Classes, mlp.Classes)
+	}
+	if !reflect.DeepEqual(loaded.optimizer.State(), mlp.optimizer.State()) {
+		t.Errorf("optimizer state changed across the round trip")
+	}
+	for _, row := range data[:20] {
+		want, _ := mlp.PredictProbabilities(row)
+		got, err := loaded.PredictProbabilities(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got outputs %v, want %v", got, want)
+		}
+	}
+
+	dir := t.TempDir()
+	if err = mlp.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	fromDir, err := NewClassifierFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameNetworks(t, fromDir.network, mlp.network)
+
+	if _, err = NewRegressorFromDir(dir); err != ErrModelKind {
+		t.Errorf("got error %v, want %v", err, ErrModelKind)
+	}
+}
+
+func TestLoadRejectsUnknownVersions(t *testing.T) {
+	mlp, _, _ := testClassifier(t, []int{2, 4, 3}, 1)
+	var buffer bytes.Buffer
+	if err := mlp.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	saved := buffer.String()
+	for _, replacement := range []string{`"version":0`, `"version":2`} {
+		_, err := LoadClassifier(strings.NewReader(strings.Replace(saved, `"version":1`, replacement, 1)))
+		if err != ErrModelVersion {
+			t.Errorf("%s: got error %v, want %v", replacement, err, ErrModelVersion)
+		}
+	}
+	if _, err := LoadClassifier(strings.NewReader(strings.Replace(saved, `"format":"gomlp"`, `"format":"other"`, 1))); err != ErrModelFormat {
+		t.Errorf("got error %v, want %v", err, ErrModelFormat)
+	}
+}
+
+// legacyFiles returns the separate CSV files an earlier release wrote for a classifier with a single hidden layer
+func legacyFiles(t *testing.T, mlp *Classifier) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	weightsFiles, biasFiles := layerFileNames(len(mlp.layers))
+	for i, layer := range mlp.layers {
+		for name, m := range map[string]*Matrix{weightsFiles[i]: layer.weights, biasFiles[i]: layer.bias} {
+			var buffer bytes.Buffer
+			if err := writeData(&buffer, m.data); err != nil {
+				t.Fatal(err)
+			}
+			files[name] = buffer.Bytes()
+		}
+	}
+	var buffer bytes.Buffer
+	if err := writeData(&buffer, [][]float64{mlp.Classes}); err != nil {
+		t.Fatal(err)
+	}
+	files[classesFile] = buffer.Bytes()
+	return files
+}
+
+func TestLoadMigratesLegacyModels(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 3}, 3)
+	if err := mlp.SetActivations([]string{Sigmoid, Sigmoid}); err != nil {
+		t.Fatal(err)
+	}
+	// earlier releases kept six decimals so the weights are rounded to what they wrote
+	for _, layer := range mlp.layers {
+		for _, m := range []*Matrix{layer.weights, layer.bias} {
+			for _, row := range m.data {
+				for c, value := range row {
+					row[c], _ = strconv.ParseFloat(strconv.FormatFloat(value, 'f', 6, 64), 64)
+				}
+			}
+		}
+	}
+	files := legacyFiles(t, mlp)
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(contents); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fromArchive, err := LoadClassifier(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromDir, err := NewClassifierFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, loaded := range []*Classifier{fromArchive, fromDir} {
+		sameNetworks(t, loaded.network, mlp.network)
+		if !reflect.DeepEqual(loaded.Classes, mlp.Classes) {
+			t.Errorf("got classes %v, want %v", loaded.Classes, mlp.Classes)
+		}
+		for _, row := range data[:20] {
+			want, _ := mlp.Predict(row)
+			got, err := loaded.Predict(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("got class %d, want %d", got, want)
+			}
+		}
+	}
+
+}
+
+func TestCheckpointsAreWrittenToTheConfiguredDirectory(t *testing.T) {
+	data, target := testData(50)
+	dir := t.TempDir()
+	mlp, err := NewClassifierWithLayers([]int{2, 4, 3}, WithSeed(1), WithCheckpoints(dir, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.Train(data, target, 5); err != nil {
+		t.Fatal(err)
+	}
+	for _, epoch := range []string{"epoch_2", "epoch_4"} {
+		if _, err = NewClassifierFromDir(filepath.Join(dir, epoch)); err != nil {
+			t.Errorf("%s: %v", epoch, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "epoch_5")); !os.IsNotExist(err) {
+		t.Errorf("got a checkpoint after epoch 5 with error %v", err)
+	}
+	if _, err = NewClassifierWithLayers([]int{2, 4, 3}, WithCheckpoints("", 1)); err != ErrCheckpoint {
+		t.Errorf("got error %v, want %v", err, ErrCheckpoint)
+	}
+}
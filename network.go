@@ -0,0 +1,582 @@
+package gomlp
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"time"
+)
+
+// NewLayer return a new pointer to a fully connected Layer
+func NewLayer(inputNodes, outputNodes int) (*Layer, error) {
+	if inputNodes < 0 || outputNodes < 0 {
+		return &Layer{}, ErrNodeValue
+	}
+
+	weights, err := NewMatrix(outputNodes, inputNodes)
+	if err != nil {
+		return &Layer{}, err
+	}
+	bias, err := NewMatrix(outputNodes, 1)
+	if err != nil {
+		return &Layer{}, err
+	}
+
+	return &Layer{
+		inputNodes,
+		outputNodes,
+		weights,
+		bias,
+		sigmoid,
+		GlorotUniform(),
+		Zeros(),
+	}, nil
+}
+
+// newLayerFromMatrices return a new pointer to a Layer built around existing weights and bias
+func newLayerFromMatrices(weights, bias *Matrix) (*Layer, error) {
+	if bias.rows != weights.rows || bias.cols != 1 {
+		return &Layer{}, ErrRowColumnDimension
+	}
+
+	var weightsInit, biasInit Initializer
+
+	return &Layer{
+		weights.cols,
+		weights.rows,
+		weights,
+		bias,
+		sigmoid,
+		weightsInit,
+		biasInit,
+	}, nil
+}
+
+// Activation returns the name of the activation function of the layer
+func (l *Layer) Activation() string {
+	return l.activationFunc.name
+}
+
+// SetActivation sets the activation function of the layer to the one registered under a name
+func (l *Layer) SetActivation(name string) error {
+	activationFunc, err := GetActivation(name)
+	if err != nil {
+		return err
+	}
+	l.activationFunc = activationFunc
+	return nil
+}
+
+// Initializers returns the initialization schemes of the weights and the bias of the layer
+func (l *Layer) Initializers() (Initializer, Initializer) {
+	return l.weightsInit, l.biasInit
+}
+
+// Initialize sets the weights and the bias of the layer using its initialization schemes
+func (l *Layer) Initialize(random *rand.Rand) error {
+	err := l.weightsInit.Initialize(l.weights, random)
+	if err != nil {
+		return err
+	}
+	return l.biasInit.Initialize(l.bias, random)
+}
+
+// forward computes the weighted input and the activated output of the layer for the given input
+func (l *Layer) forward(input *Matrix) (*Matrix, *Matrix, error) {
+	z, err := Multiply(l.weights, input)
+	if err != nil {
+		return &Matrix{}, &Matrix{}, err
+	}
+	z, err = AddColumnVector(z, l.bias)
+	if err != nil {
+		return &Matrix{}, &Matrix{}, err
+	}
+
+	return z, l.activationFunc.apply(z), nil
+}
+
+// newNetwork return a new pointer to a network with a layer between every pair of sizes
+func newNetwork(layerSizes []int, options ...Option) (*network, error) {
+	if len(layerSizes) < 2 {
+		return &network{}, ErrLayerCount
+	}
+	for _, size := range layerSizes {
+		if size < 0 {
+			return &network{}, ErrNodeValue
+		}
+	}
+
+	layers := make([]*Layer, len(layerSizes)-1)
+	for i := range layers {
+		layer, err := NewLayer(layerSizes[i], layerSizes[i+1])
+		if err != nil {
+			return &network{}, err
+		}
+		layers[i] = layer
+	}
+
+	sizes := make([]int, len(layerSizes))
+	copy(sizes, layerSizes)
+
+	optimizer := NewSGD(0.01)
+	lossFunc := meanSquaredError
+	batchSize := 1
+	var sampler Sampler = NewShuffleSampler()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var checkpointDir string
+	var checkpointEvery int
+	var preprocessing *attachedPreprocessing
+	var quantization *FixedPointConfig
+	var masks []*Matrix
+	var epochLosses []float64
+
+	nn := &network{
+		sizes,
+		layers,
+		optimizer,
+		lossFunc,
+		batchSize,
+		sampler,
+		random,
+		checkpointDir,
+		checkpointEvery,
+		preprocessing,
+		quantization,
+		masks,
+		epochLosses,
+	}
+	for _, option := range options {
+		err := option(nn)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+
+	for _, layer := range nn.layers {
+		err := layer.Initialize(nn.random)
+		if err != nil {
+			return &network{}, err
+		}
+	}
+	return nn, nil
+}
+
+// newNetworkFromLayers return a new pointer to a network built around existing layers
+func newNetworkFromLayers(layers []*Layer) (*network, error) {
+	if len(layers) < 1 {
+		return &network{}, ErrLayerCount
+	}
+
+	layerSizes := []int{layers[0].inputNodes}
+	for i, layer := range layers {
+		if i > 0 && layer.inputNodes != layers[i-1].outputNodes {
+			return &network{}, ErrLayerDimension
+		}
+		layerSizes = append(layerSizes, layer.outputNodes)
+	}
+
+	optimizer := NewSGD(0.01)
+	lossFunc := meanSquaredError
+	batchSize := 1
+	var sampler Sampler = NewShuffleSampler()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var checkpointDir string
+	var checkpointEvery int
+	var preprocessing *attachedPreprocessing
+	var quantization *FixedPointConfig
+	var masks []*Matrix
+	var epochLosses []float64
+
+	return &network{
+		layerSizes,
+		layers,
+		optimizer,
+		lossFunc,
+		batchSize,
+		sampler,
+		random,
+		checkpointDir,
+		checkpointEvery,
+		preprocessing,
+		quantization,
+		masks,
+		epochLosses,
+	}, nil
+}
+
+// LayerSizes returns the number of nodes in every layer starting with the input layer
+func (nn *network) LayerSizes() []int {
+	sizes := make([]int, len(nn.layerSizes))
+	copy(sizes, nn.layerSizes)
+	return sizes
+}
+
+// inputNodes returns the number of nodes in the input layer
+func (nn *network) inputNodes() int {
+	return nn.layerSizes[0]
+}
+
+// outputNodes returns the number of nodes in the output layer
+func (nn *network) outputNodes() int {
+	return nn.layerSizes[len(nn.layerSizes)-1]
+}
+
+// Layers returns the layers of the network starting with the first hidden layer
+func (nn *network) Layers() []*Layer {
+	layers := make([]*Layer, len(nn.layers))
+	copy(layers, nn.layers)
+	return layers
+}
+
+// Activations returns the names of the activation functions of every layer
+func (nn *network) Activations() []string {
+	names := make([]string, len(nn.layers))
+	for i, layer := range nn.layers {
+		names[i] = layer.Activation()
+	}
+	return names
+}
+
+// SetActivation sets the activation function of a layer to the one registered under a name
+func (nn *network) SetActivation(layer int, name string) error {
+	if layer < 0 || layer >= len(nn.layers) {
+		return ErrLayerIndex
+	}
+	return nn.layers[layer].SetActivation(name)
+}
+
+// SetActivations sets the activation functions of every layer to the ones registered under the names
+func (nn *network) SetActivations(names []string) error {
+	if len(names) != len(nn.layers) {
+		return ErrActivationCount
+	}
+	for i, name := range names {
+		err := nn.SetActivation(i, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Optimizer returns the optimizer used to update the weights during training
+func (nn *network) Optimizer() Optimizer {
+	return nn.optimizer
+}
+
+// SetOptimizer sets the optimizer used to update the weights during training
+func (nn *network) SetOptimizer(optimizer Optimizer) error {
+	if optimizer == nil {
+		return ErrUnknownOptimizer
+	}
+	nn.optimizer = optimizer
+	return nil
+}
+
+// BatchSize returns the number of samples averaged into every weight update
+func (nn *network) BatchSize() int {
+	return nn.batchSize
+}
+
+// SetBatchSize sets the number of samples averaged into every weight update
+func (nn *network) SetBatchSize(batchSize int) error {
+	if batchSize < 1 {
+		return ErrBatchSize
+	}
+	nn.batchSize = batchSize
+	return nil
+}
+
+// Sampler returns the sampler deciding the order of the rows in every epoch
+func (nn *network) Sampler() Sampler {
+	return nn.sampler
+}
+
+// SetSampler sets the sampler deciding the order of the rows in every epoch
+func (nn *network) SetSampler(sampler Sampler) error {
+	if sampler == nil {
+		return ErrSampler
+	}
+	nn.sampler = sampler
+	return nil
+}
+
+// SetRandomSource sets the source of randomness used for weight initialization and sampling
+func (nn *network) SetRandomSource(source rand.Source) error {
+	if source == nil {
+		return ErrRandomSource
+	}
+	nn.random = rand.New(source)
+	return nil
+}
+
+// Initializers returns the initialization schemes of every layer as weights and bias pairs in the form of String
+func (nn *network) Initializers() []string {
+	var names []string
+	for _, layer := range nn.layers {
+		names = append(names, layer.weightsInit.String(), layer.biasInit.String())
+	}
+	return names
+}
+
+// SetInitializer sets the initialization schemes of a layer and initializes its weights and bias again
+func (nn *network) SetInitializer(layer int, weightsInit, biasInit Initializer) error {
+	err := nn.setInitializer(layer, weightsInit, biasInit)
+	if err != nil {
+		return err
+	}
+	return nn.layers[layer].Initialize(nn.random)
+}
+
+// setInitializer records the initialization schemes of a layer without initializing it
+func (nn *network) setInitializer(layer int, weightsInit, biasInit Initializer) error {
+	if layer < 0 || layer >= len(nn.layers) {
+		return ErrLayerIndex
+	}
+	for _, in := range []Initializer{weightsInit, biasInit} {
+		_, err := ParseInitializer(in.String())
+		if err != nil || in.name == "" {
+			return ErrUnknownInitializer
+		}
+	}
+	nn.layers[layer].weightsInit = weightsInit
+	nn.layers[layer].biasInit = biasInit
+	return nil
+}
+
+// ReadInitializers records the initialization schemes of every layer from a CSV file
+func (nn *network) ReadInitializers(filename string) error {
+	names, err := ReadNames(filename)
+	if err != nil {
+		return err
+	}
+	return nn.setInitializers(names)
+}
+
+// setInitializers records the initialization schemes of every layer from weights and bias pairs in the form of String
+func (nn *network) setInitializers(names []string) error {
+	if len(names) != 2*len(nn.layers) {
+		return ErrInitializerParameters
+	}
+
+	for i, layer := range nn.layers {
+		weightsInit, err := ParseInitializer(names[2*i])
+		if err != nil {
+			return err
+		}
+		biasInit, err := ParseInitializer(names[2*i+1])
+		if err != nil {
+			return err
+		}
+		layer.weightsInit = weightsInit
+		layer.biasInit = biasInit
+	}
+	return nil
+}
+
+// SetCheckpoints saves the model into a new sub directory of a directory every given number of epochs
+// An interval of zero turns checkpointing off
+func (nn *network) SetCheckpoints(dir string, every int) error {
+	if every < 0 || (every > 0 && dir == "") {
+		return ErrCheckpoint
+	}
+	nn.checkpointDir = dir
+	nn.checkpointEvery = every
+	return nil
+}
+
+// Loss returns the name of the loss function minimized during training
+func (nn *network) Loss() string {
+	return nn.lossFunc.name
+}
+
+// SetLoss sets the loss function minimized during training to the one with the given name
+func (nn *network) SetLoss(name string) error {
+	lossFunc, err := GetLoss(name)
+	if err != nil {
+		return err
+	}
+	nn.lossFunc = lossFunc
+	return nil
+}
+
+// LossHistory returns the mean loss of every epoch of the last training
+func (nn *network) LossHistory() []float64 {
+	losses := make([]float64, len(nn.epochLosses))
+	copy(losses, nn.epochLosses)
+	return losses
+}
+
+// ReadActivations sets the activation functions of every layer from a CSV file of names
+func (nn *network) ReadActivations(filename string) error {
+	names, err := ReadNames(filename)
+	if err != nil {
+		return err
+	}
+	return nn.SetActivations(names)
+}
+
+// feedForward returns the weighted inputs and the outputs of every layer
+// The outputs start with the inputs themselves and so hold one Matrix more than the weighted inputs
+func (nn *network) feedForward(inputs *Matrix) ([]*Matrix, []*Matrix, error) {
+	zs := make([]*Matrix, len(nn.layers))
+	activations := make([]*Matrix, len(nn.layers)+1)
+	activations[0] = inputs
+	if nn.quantization != nil {
+		activations[0] = nn.quantization.fakeQuantize(inputs, nn.quantization.activations)
+	}
+	for i, layer := range nn.layers {
+		forward := layer.forward
+		if nn.quantization != nil {
+			forward = nn.quantization.forward(layer)
+		}
+		z, output, err := forward(activations[i])
+		if err != nil {
+			return zs, activations, err
+		}
+		zs[i] = z
+		activations[i+1] = output
+	}
+	return zs, activations, nil
+}
+
+// backPropagate returns the gradients of the loss with respect to the weights and biases of every layer
+// Every column of the activations is a sample and the gradients are averaged over the samples
+// It also returns the mean loss of the forward pass the weighted inputs and activations come from
+func (nn *network) backPropagate(zs, activations []*Matrix, target *Matrix) ([]*Matrix, []*Matrix, float64, error) {
+	last := len(nn.layers) - 1
+	weightsGradients := make([]*Matrix, len(nn.layers))
+	biasGradients := make([]*Matrix, len(nn.layers))
+	output := activations[last+1]
+	loss, err := nn.lossFunc.loss(output, target)
+	if err != nil {
+		return weightsGradients, biasGradients, loss, err
+	}
+
+	gradients, err := nn.lossFunc.outputGradient(zs[last], output, target, nn.layers[last].activationFunc)
+	if err != nil {
+		return weightsGradients, biasGradients, loss, err
+	}
+	gradients.Multiply(1 / float64(output.cols))
+
+	for i := last; i >= 0; i-- {
+		layer := nn.layers[i]
+		if i < last {
+			gradients, err = layer.activationFunc.backward(zs[i], activations[i+1], gradients)
+			if err != nil {
+				return weightsGradients, biasGradients, loss, err
+			}
+		}
+
+		weightsGradients[i], err = Multiply(gradients, activations[i].Transpose())
+		if err != nil {
+			return weightsGradients, biasGradients, loss, err
+		}
+		biasGradients[i] = gradients.SumColumns()
+
+		if i > 0 {
+			weights := layer.weights
+			if nn.quantization != nil {
+				weights = nn.quantization.fakeQuantize(weights, nn.quantization.weights)
+			}
+			gradients, err = Multiply(weights.Transpose(), gradients)
+			if err != nil {
+				return weightsGradients, biasGradients, loss, err
+			}
+		}
+	}
+	return weightsGradients, biasGradients, loss, nil
+}
+
+// applyGradients updates the weights and biases of every layer with the optimizer of the network
+// The weights of layer i are optimizer parameter 2*i and its bias is parameter 2*i+1
+func (nn *network) applyGradients(weightsGradients, biasGradients []*Matrix) error {
+	nn.optimizer.Step()
+	for i, layer := range nn.layers {
+		err := nn.optimizer.Update(2*i, layer.weights, weightsGradients[i])
+		if err != nil {
+			return err
+		}
+		err = nn.optimizer.Update(2*i+1, layer.bias, biasGradients[i])
+		if err != nil {
+			return err
+		}
+	}
+	nn.applyMasks()
+	return nil
+}
+
+// train fits the network to the inputs and the already transformed targets
+// The save function is called with a new directory whenever a checkpoint is due
+func (nn *network) train(data, targetArr [][]float64, epochs int, save func(string) error) error {
+	if len(data) == 0 || len(data) != len(targetArr) {
+		return ErrRowColumnDimension
+	}
+
+	nn.epochLosses = make([]float64, 0, epochs)
+	for iter := 0; iter < epochs; iter++ {
+		indices, err := nn.sampler.Indices(len(data), nn.random)
+		if err != nil {
+			return err
+		}
+		batches, err := Batches(indices, nn.batchSize)
+		if err != nil {
+			return err
+		}
+
+		var epochLoss float64
+		for _, batch := range batches {
+			loss, err := nn.trainBatch(data, targetArr, batch)
+			if err != nil {
+				return err
+			}
+			epochLoss = epochLoss + loss*float64(len(batch))
+		}
+		nn.epochLosses = append(nn.epochLosses, epochLoss/float64(len(indices)))
+
+		if nn.checkpointEvery > 0 && (iter+1)%nn.checkpointEvery == 0 {
+			err = save(filepath.Join(nn.checkpointDir, fmt.Sprintf("epoch_%d", iter+1)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// trainBatch runs a single forward and backward pass over the selected rows and updates the weights
+// It returns the mean loss of the batch
+func (nn *network) trainBatch(data, targetArr [][]float64, indices []int) (float64, error) {
+	inputs, err := ConvertFromRowsToMatrix(data, indices)
+	if err != nil {
+		return 0, err
+	}
+	target, err := ConvertFromRowsToMatrix(targetArr, indices)
+	if err != nil {
+		return 0, err
+	}
+
+	zs, activations, err := nn.feedForward(inputs)
+	if err != nil {
+		return 0, err
+	}
+
+	weightsGradients, biasGradients, loss, err := nn.backPropagate(zs, activations, target)
+	if err != nil {
+		return loss, err
+	}
+	return loss, nn.applyGradients(weightsGradients, biasGradients)
+}
+
+// predict returns the output of the network for a single input row
+func (nn *network) predict(inputArr []float64) (*Matrix, error) {
+	inputs, err := ConvertFromArrayToMatrix1D(inputArr)
+	if err != nil {
+		return &Matrix{}, err
+	}
+	_, activations, err := nn.feedForward(inputs)
+	if err != nil {
+		return &Matrix{}, err
+	}
+	return activations[len(activations)-1], nil
+}
@@ -0,0 +1,184 @@
+package gomlp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testData returns a two feature dataset with three classes that a small network separates
+func testData(rows int) ([][]float64, [][]float64) {
+	random := rand.New(rand.NewSource(3))
+	data := make([][]float64, rows)
+	target := make([][]float64, rows)
+	for i := range data {
+		x, y := random.Float64()*2-1, random.Float64()*2-1
+		class := 0.0
+		if x*y > 0 {
+			class = 1
+		}
+		if x > 0.5 {
+			class = 2
+		}
+		data[i] = []float64{x, y}
+		target[i] = []float64{class}
+	}
+	return data, target
+}
+
+// testClassifier returns a classifier with a tanh hidden layer and a softmax output trained on testData
+func testClassifier(t *testing.T, sizes []int, epochs int) (*Classifier, [][]float64, [][]float64) {
+	t.Helper()
+	data, target := testData(300)
+	mlp, err := NewClassifierWithLayers(sizes, WithSeed(1), WithOptimizer(NewAdam(0.01, 0.9, 0.999, 1e-8)), WithBatchSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	activations := make([]string, len(sizes)-1)
+	for i := range activations {
+		activations[i] = Tanh
+	}
+	activations[len(activations)-1] = Softmax
+	if err = mlp.SetActivations(activations); err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.SetLoss(CategoricalCrossEntropy); err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.Train(data, target, epochs); err != nil {
+		t.Fatal(err)
+	}
+	return mlp, data, target
+}
+
+// sameNetworks fails the test if two networks differ in shape, activations or any weight
+func sameNetworks(t *testing.T, got, want *network) {
+	t.Helper()
+	if len(got.layers) != len(want.layers) {
+		t.Fatalf("got %d layers, want %d", len(got.layers), len(want.layers))
+	}
+	for i := range want.layers {
+		if got.layers[i].activationFunc.name != want.layers[i].activationFunc.name {
+			t.Errorf("layer %d: got activation %s, want %s", i, got.layers[i].activationFunc.name, want.layers[i].activationFunc.name)
+		}
+		for _, pair := range [][2]*Matrix{{got.layers[i].weights, want.layers[i].weights}, {got.layers[i].bias, want.layers[i].bias}} {
+			if pair[0].rows != pair[1].rows || pair[0].cols != pair[1].cols {
+				t.Fatalf("layer %d: got %dx%d, want %dx%d", i, pair[0].rows, pair[0].cols, pair[1].rows, pair[1].cols)
+			}
+			for r := range pair[1].data {
+				for c := range pair[1].data[r] {
+					if pair[0].data[r][c] != pair[1].data[r][c] {
+						t.Fatalf("layer %d [%d][%d]: got %v, want %v", i, r, c, pair[0].data[r][c], pair[1].data[r][c])
+					}
+				}
+			}
+		}
+	}
+}
+
+// matchesNumericalGradients fails the test if backPropagate differs from central differences of the loss
+func matchesNumericalGradients(t *testing.T, activations []string, loss string, targetArr [][]float64) {
+	t.Helper()
+	nn, err := newNetwork([]int{3, 5, 4, 2}, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = nn.SetActivations(activations); err != nil {
+		t.Fatal(err)
+	}
+	if err = nn.SetLoss(loss); err != nil {
+		t.Fatal(err)
+	}
+	inputs, _ := ConvertFromArray2DToMatrix([][]float64{{0.5, -0.3, 0.8}, {-0.1, 0.4, -0.9}}) // one sample per column
+	inputs = inputs.Transpose()
+	target, _ := ConvertFromArray2DToMatrix(targetArr)
+	target = target.Transpose()
+
+	lossValue := func() float64 {
+		_, activations, err := nn.feedForward(inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := nn.lossFunc.loss(activations[len(activations)-1], target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	zs, outputs, err := nn.feedForward(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weightsGradients, biasGradients, _, err := nn.backPropagate(zs, outputs, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step := 1e-6
+	for i, layer := range nn.layers {
+		for _, pair := range []struct {
+			params, gradients *Matrix
+		}{{layer.weights, weightsGradients[i]}, {layer.bias, biasGradients[i]}} {
+			for r := range pair.params.data {
+				for c := range pair.params.data[r] {
+					original := pair.params.data[r][c]
+					pair.params.data[r][c] = original + step
+					up := lossValue()
+					pair.params.data[r][c] = original - step
+					down := lossValue()
+					pair.params.data[r][c] = original
+					numerical := (up - down) / (2 * step)
+					if math.Abs(numerical-pair.gradients.data[r][c]) > 1e-6 {
+						t.Errorf("%v %s layer %d [%d][%d]: got gradient %v, want %v", activations, loss, i, r, c, pair.gradients.data[r][c], numerical)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBackPropagateMatchesNumericalGradients(t *testing.T) {
+	matchesNumericalGradients(t, []string{Sigmoid, Sigmoid, Sigmoid}, MeanSquaredError, [][]float64{{0.2, 0.9}, {0.7, 0.1}})
+}
+
+func TestArbitraryDepthClassifierLearns(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 6, 3}, 60)
+	if sizes := mlp.LayerSizes(); len(sizes) != 5 || sizes[2] != 8 {
+		t.Fatalf("got layer sizes %v", sizes)
+	}
+	score, err := mlp.Score(data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score < 0.9 {
+		t.Errorf("got accuracy %v, want at least 0.9", score)
+	}
+	losses := mlp.LossHistory()
+	if len(losses) != 60 || losses[len(losses)-1] >= losses[0] {
+		t.Errorf("loss did not decrease: first %v last %v", losses[0], losses[len(losses)-1])
+	}
+}
+
+func TestFullBatchIgnoresRowOrder(t *testing.T) {
+	data, target := testData(64)
+	var trained []*Classifier
+	for _, sampler := range []Sampler{NewSequentialSampler(), NewShuffleSampler()} {
+		mlp, err := NewClassifierWithLayers([]int{2, 8, 3}, WithSeed(1), WithOptimizer(NewSGD(0.1)), WithBatchSize(len(data)), WithSampler(sampler))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = mlp.Train(data, target, 5); err != nil {
+			t.Fatal(err)
+		}
+		trained = append(trained, mlp)
+	}
+	for i := range trained[0].layers {
+		for r, row := range trained[0].layers[i].weights.data {
+			for c, weight := range row {
+				if math.Abs(weight-trained[1].layers[i].weights.data[r][c]) > 1e-12 {
+					t.Fatalf("layer %d [%d][%d]: got %v and %v", i, r, c, weight, trained[1].layers[i].weights.data[r][c])
+				}
+			}
+		}
+	}
+}
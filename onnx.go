@@ -0,0 +1,636 @@
+package gomlp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Versions and enumerations of the ONNX format written by ExportONNX
+const (
+	onnxIRVersion      = 7
+	onnxOpsetVersion   = 13
+	onnxFloat          = 1
+	onnxDouble         = 11
+	onnxAttributeFloat = 1
+	onnxAttributeInt   = 2
+)
+
+var onnxInput = "input"
+
+var onnxOutput = "output"
+
+// onnxActivations maps the activation functions to the ONNX operators computing them
+// GELU has no operator in the exported opset and is written as a chain of elementwise operators
+var onnxActivations = map[string]string{
+	Sigmoid:   "Sigmoid",
+	Tanh:      "Tanh",
+	ReLU:      "Relu",
+	LeakyReLU: "LeakyRelu",
+	ELU:       "Elu",
+	Softplus:  "Softplus",
+	Identity:  "Identity",
+	Softmax:   "Softmax",
+}
+
+// onnxTensor is the Data Structure to hold a decoded ONNX initializer
+type onnxTensor struct {
+	dims   []int64
+	values []float64
+}
+
+// onnxNode is the Data Structure to hold a decoded ONNX node
+type onnxNode struct {
+	opType  string
+	inputs  []string
+	outputs []string
+	floats  map[string]float64
+	ints    map[string]int64
+}
+
+// encodeONNXTensor returns a TensorProto holding float32 values
+func encodeONNXTensor(name string, dims []int64, values []float64) []byte {
+	var tensor []byte
+	for _, dim := range dims {
+		tensor = appendVarintField(tensor, 1, uint64(dim))
+	}
+	tensor = appendVarintField(tensor, 2, onnxFloat)
+	tensor = appendStringField(tensor, 8, name)
+	raw := make([]byte, 0, 4*len(values))
+	for _, value := range values {
+		raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(float32(value)))
+	}
+	return appendBytesField(tensor, 9, raw)
+}
+
+// encodeONNXValueInfo returns a ValueInfoProto for a float tensor with a batch dimension and the given features
+func encodeONNXValueInfo(name string, features int) []byte {
+	var batch, feature, shape, tensorType, typeProto, info []byte
+	batch = appendStringField(batch, 2, "N")
+	feature = appendVarintField(feature, 1, uint64(features))
+	shape = appendBytesField(shape, 1, batch)
+	shape = appendBytesField(shape, 1, feature)
+	tensorType = appendVarintField(tensorType, 1, onnxFloat)
+	tensorType = appendBytesField(tensorType, 2, shape)
+	typeProto = appendBytesField(typeProto, 1, tensorType)
+	info = appendStringField(info, 1, name)
+	return appendBytesField(info, 2, typeProto)
+}
+
+// encodeONNXNode returns a NodeProto with the given encoded attributes
+func encodeONNXNode(opType, name string, inputs, outputs []string, attributes ...[]byte) []byte {
+	var node []byte
+	for _, input := range inputs {
+		node = appendStringField(node, 1, input)
+	}
+	for _, output := range outputs {
+		node = appendStringField(node, 2, output)
+	}
+	node = appendStringField(node, 3, name)
+	node = appendStringField(node, 4, opType)
+	for _, attribute := range attributes {
+		node = appendBytesField(node, 5, attribute)
+	}
+	return node
+}
+
+// encodeONNXFloatAttribute returns an AttributeProto holding a float
+func encodeONNXFloatAttribute(name string, value float64) []byte {
+	var attribute []byte
+	attribute = appendStringField(attribute, 1, name)
+	attribute = appendFloat32Field(attribute, 2, float32(value))
+	return appendVarintField(attribute, 20, onnxAttributeFloat)
+}
+
+// encodeONNXIntAttribute returns an AttributeProto holding an integer
+func encodeONNXIntAttribute(name string, value int64) []byte {
+	var attribute []byte
+	attribute = appendStringField(attribute, 1, name)
+	attribute = appendVarintField(attribute, 3, uint64(value))
+	return appendVarintField(attribute, 20, onnxAttributeInt)
+}
+
+// encodeONNXMetadata returns a StringStringEntryProto
+func encodeONNXMetadata(key, value string) []byte {
+	var entry []byte
+	entry = appendStringField(entry, 1, key)
+	return appendStringField(entry, 2, value)
+}
+
+// writeONNX writes the network as an ONNX model with a Gemm, an Add and an activation per layer
+func (nn *network) writeONNX(w io.Writer, kind string, classes []float64) error {
+	var graph, initializers []byte
+	current := onnxInput
+	for i, layer := range nn.layers {
+		prefix := fmt.Sprintf("layer%d", i)
+		output := prefix + "_output"
+		if i == len(nn.layers)-1 {
+			output = onnxOutput
+		}
+
+		initializers = appendBytesField(initializers, 5, encodeONNXTensor(prefix+"_weights", []int64{int64(layer.outputNodes), int64(layer.inputNodes)}, layer.weights.ConvertFromMatrixToArray1D()))
+		initializers = appendBytesField(initializers, 5, encodeONNXTensor(prefix+"_bias", []int64{int64(layer.outputNodes)}, layer.bias.ConvertFromMatrixToArray1D()))
+		graph = appendBytesField(graph, 1, encodeONNXNode("Gemm", prefix+"_gemm", []string{current, prefix + "_weights"}, []string{prefix + "_product"}, encodeONNXIntAttribute("transB", 1)))
+		graph = appendBytesField(graph, 1, encodeONNXNode("Add", prefix+"_add", []string{prefix + "_product", prefix + "_bias"}, []string{prefix + "_linear"}))
+		linear := prefix + "_linear"
+
+		name := layer.activationFunc.name
+		switch name {
+		case GELU:
+			initializers = appendBytesField(initializers, 5, encodeONNXTensor(prefix+"_sqrt2", []int64{}, []float64{math.Sqrt2}))
+			initializers = appendBytesField(initializers, 5, encodeONNXTensor(prefix+"_one", []int64{}, []float64{1}))
+			initializers = appendBytesField(initializers, 5, encodeONNXTensor(prefix+"_half", []int64{}, []float64{0.5}))
+			graph = appendBytesField(graph, 1, encodeONNXNode("Div", prefix+"_gelu_div", []string{linear, prefix + "_sqrt2"}, []string{prefix + "_gelu_scaled"}))
+			graph = appendBytesField(graph, 1, encodeONNXNode("Erf", prefix+"_gelu_erf", []string{prefix + "_gelu_scaled"}, []string{prefix + "_gelu_erf"}))
+			graph = appendBytesField(graph, 1, encodeONNXNode("Add", prefix+"_gelu_add", []string{prefix + "_gelu_erf", prefix + "_one"}, []string{prefix + "_gelu_shifted"}))
+			graph = appendBytesField(graph, 1, encodeONNXNode("Mul", prefix+"_gelu_mul", []string{linear, prefix + "_gelu_shifted"}, []string{prefix + "_gelu_product"}))
+			graph = appendBytesField(graph, 1, encodeONNXNode("Mul", prefix+"_gelu_half", []string{prefix + "_gelu_product", prefix + "_half"}, []string{output}))
+		case LeakyReLU:
+			graph = appendBytesField(graph, 1, encodeONNXNode(onnxActivations[name], prefix+"_activation", []string{linear}, []string{output}, encodeONNXFloatAttribute("alpha", leakyReLUSlope)))
+		case ELU:
+			graph = appendBytesField(graph, 1, encodeONNXNode(onnxActivations[name], prefix+"_activation", []string{linear}, []string{output}, encodeONNXFloatAttribute("alpha", eluAlpha)))
+		case Softmax:
+			graph = appendBytesField(graph, 1, encodeONNXNode(onnxActivations[name], prefix+"_activation", []string{linear}, []string{output}, encodeONNXIntAttribute("axis", 1)))
+		default:
+			opType, ok := onnxActivations[name]
+			if !ok {
+				return ErrONNXUnsupported
+			}
+			graph = appendBytesField(graph, 1, encodeONNXNode(opType, prefix+"_activation", []string{linear}, []string{output}))
+		}
+		current = output
+	}
+
+	graph = appendStringField(graph, 2, "gomlp")
+	graph = append(graph, initializers...)
+	graph = appendBytesField(graph, 11, encodeONNXValueInfo(onnxInput, nn.inputNodes()))
+	graph = appendBytesField(graph, 12, encodeONNXValueInfo(onnxOutput, nn.outputNodes()))
+
+	var opset, model []byte
+	opset = appendVarintField(opset, 2, onnxOpsetVersion)
+	model = appendVarintField(model, 1, onnxIRVersion)
+	model = appendStringField(model, 2, "gomlp")
+	model = appendBytesField(model, 7, graph)
+	model = appendBytesField(model, 8, opset)
+	model = appendBytesField(model, 14, encodeONNXMetadata("kind", kind))
+	model = appendBytesField(model, 14, encodeONNXMetadata("activations", strings.Join(nn.Activations(), ",")))
+	if len(classes) > 0 {
+		labels := make([]string, len(classes))
+		for i, class := range classes {
+			labels[i] = strconv.FormatFloat(class, 'g', -1, 64)
+		}
+		model = appendBytesField(model, 14, encodeONNXMetadata("classes", strings.Join(labels, ",")))
+	}
+
+	_, err := w.Write(model)
+	return err
+}
+
+// decodeONNXTensor decodes a TensorProto holding float or double values
+func decodeONNXTensor(data []byte) (string, onnxTensor, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return "", onnxTensor{}, err
+	}
+	dims, err := protoInts(fields, 1)
+	if err != nil {
+		return "", onnxTensor{}, err
+	}
+	dataType, err := protoInts(fields, 2)
+	if err != nil || len(dataType) != 1 {
+		return "", onnxTensor{}, ErrONNXUnsupported
+	}
+
+	// every value takes at least four bytes of the tensor so larger shapes are malformed
+	size := 1
+	limit := len(data) / 4
+	for _, dim := range dims {
+		if dim < 0 || (dim > 0 && int64(size) > int64(limit)/dim) {
+			return "", onnxTensor{}, ErrONNXUnsupported
+		}
+		size = size * int(dim)
+	}
+	values := make([]float64, 0, size)
+	raw := protoMessages(fields, 9)
+	switch {
+	case dataType[0] == onnxFloat && len(raw) == 1:
+		for i := 0; i+4 <= len(raw[0]); i = i + 4 {
+			values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[0][i:]))))
+		}
+	case dataType[0] == onnxDouble && len(raw) == 1:
+		for i := 0; i+8 <= len(raw[0]); i = i + 8 {
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(raw[0][i:])))
+		}
+	case dataType[0] == onnxFloat:
+		for _, field := range fields {
+			if field.number == 4 && field.wire == protoFixed32 {
+				values = append(values, float64(math.Float32frombits(uint32(field.value))))
+			} else if field.number == 4 && field.wire == protoBytes {
+				for i := 0; i+4 <= len(field.data); i = i + 4 {
+					values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(field.data[i:]))))
+				}
+			}
+		}
+	case dataType[0] == onnxDouble:
+		for _, field := range fields {
+			if field.number == 10 && field.wire == protoFixed64 {
+				values = append(values, math.Float64frombits(field.value))
+			} else if field.number == 10 && field.wire == protoBytes {
+				for i := 0; i+8 <= len(field.data); i = i + 8 {
+					values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(field.data[i:])))
+				}
+			}
+		}
+	default:
+		return "", onnxTensor{}, ErrONNXUnsupported
+	}
+	if len(values) != size {
+		return "", onnxTensor{}, ErrONNXUnsupported
+	}
+	return protoString(fields, 8), onnxTensor{dims, values}, nil
+}
+
+// decodeONNXNode decodes a NodeProto with its float and integer attributes
+func decodeONNXNode(data []byte) (onnxNode, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return onnxNode{}, err
+	}
+
+	node := onnxNode{
+		opType:  protoString(fields, 4),
+		inputs:  protoStrings(fields, 1),
+		outputs: protoStrings(fields, 2),
+		floats:  make(map[string]float64),
+		ints:    make(map[string]int64),
+	}
+	if domain := protoString(fields, 7); domain != "" && domain != "ai.onnx" {
+		return node, ErrONNXUnsupported
+	}
+	for _, attribute := range protoMessages(fields, 5) {
+		attributeFields, err := parseProto(attribute)
+		if err != nil {
+			return node, err
+		}
+		name := protoString(attributeFields, 1)
+		for _, field := range attributeFields {
+			if field.number == 2 && field.wire == protoFixed32 {
+				node.floats[name] = float64(math.Float32frombits(uint32(field.value)))
+			} else if field.number == 3 && field.wire == protoVarint {
+				node.ints[name] = int64(field.value)
+			}
+		}
+	}
+	return node, nil
+}
+
+// readONNX reads an ONNX model made of Gemm or MatMul nodes followed by optional Add and activation nodes
+// It returns the network, the kind of model and the classes stored in the metadata
+func readONNX(r io.Reader) (*network, string, []float64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	modelFields, err := parseProto(data)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+
+	metadata := make(map[string]string)
+	for _, entry := range protoMessages(modelFields, 14) {
+		entryFields, err := parseProto(entry)
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		metadata[protoString(entryFields, 1)] = protoString(entryFields, 2)
+	}
+	var classes []float64
+	if metadata["classes"] != "" {
+		for _, label := range strings.Split(metadata["classes"], ",") {
+			class, err := strconv.ParseFloat(label, 64)
+			if err != nil {
+				return &network{}, "", nil, err
+			}
+			classes = append(classes, class)
+		}
+	}
+
+	graphs := protoMessages(modelFields, 7)
+	if len(graphs) != 1 {
+		return &network{}, "", nil, ErrONNXUnsupported
+	}
+	graphFields, err := parseProto(graphs[0])
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+
+	tensors := make(map[string]onnxTensor)
+	for _, initializer := range protoMessages(graphFields, 5) {
+		name, tensor, err := decodeONNXTensor(initializer)
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		tensors[name] = tensor
+	}
+
+	var current string
+	for _, input := range protoMessages(graphFields, 11) {
+		inputFields, err := parseProto(input)
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		name := protoString(inputFields, 1)
+		if _, ok := tensors[name]; !ok && current == "" {
+			current = name
+		}
+	}
+	outputs := protoMessages(graphFields, 12)
+	if current == "" || len(outputs) != 1 {
+		return &network{}, "", nil, ErrONNXUnsupported
+	}
+	outputFields, err := parseProto(outputs[0])
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	output := protoString(outputFields, 1)
+
+	var nodes []onnxNode
+	for _, data := range protoMessages(graphFields, 1) {
+		node, err := decodeONNXNode(data)
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	var layers []*Layer
+	var activations []string
+	for i := 0; i < len(nodes); {
+		layer, next, err := readONNXLinear(nodes, i, current, tensors)
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		current, err = nodeOutput(nodes[next-1])
+		if err != nil {
+			return &network{}, "", nil, err
+		}
+		i = next
+
+		activation := Identity
+		if i < len(nodes) && nodes[i].opType != "Gemm" && nodes[i].opType != "MatMul" {
+			activation, next, err = readONNXActivation(nodes, i, current, tensors)
+			if err != nil {
+				return &network{}, "", nil, err
+			}
+			current, err = nodeOutput(nodes[next-1])
+			if err != nil {
+				return &network{}, "", nil, err
+			}
+			i = next
+		}
+		layers = append(layers, layer)
+		activations = append(activations, activation)
+	}
+	if current != output {
+		return &network{}, "", nil, ErrONNXUnsupported
+	}
+
+	nn, err := newNetworkFromLayers(layers)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	err = nn.SetActivations(activations)
+	if err != nil {
+		return &network{}, "", nil, err
+	}
+	return nn, metadata["kind"], classes, nil
+}
+
+// nodeOutput returns the single output of a node
+func nodeOutput(node onnxNode) (string, error) {
+	if len(node.outputs) != 1 {
+		return "", ErrONNXUnsupported
+	}
+	return node.outputs[0], nil
+}
+
+// readONNXLinear reads a Gemm or MatMul node consuming the current tensor and an optional Add of a bias
+// It returns the layer and the index of the node following it
+func readONNXLinear(nodes []onnxNode, i int, current string, tensors map[string]onnxTensor) (*Layer, int, error) {
+	node := nodes[i]
+	if (node.opType != "Gemm" && node.opType != "MatMul") || len(node.inputs) < 2 || node.inputs[0] != current || len(node.outputs) != 1 {
+		return &Layer{}, i, ErrONNXUnsupported
+	}
+	tensor, ok := tensors[node.inputs[1]]
+	if !ok || len(tensor.dims) != 2 {
+		return &Layer{}, i, ErrONNXUnsupported
+	}
+
+	transposed := node.opType == "Gemm" && node.ints["transB"] == 1
+	if node.ints["transA"] != 0 {
+		return &Layer{}, i, ErrONNXUnsupported
+	}
+	for _, scale := range []string{"alpha", "beta"} {
+		if value, ok := node.floats[scale]; ok && value != 1 {
+			return &Layer{}, i, ErrONNXUnsupported
+		}
+	}
+
+	rows, cols := int(tensor.dims[0]), int(tensor.dims[1])
+	var layer *Layer
+	var err error
+	if transposed {
+		layer, err = NewLayer(cols, rows)
+	} else {
+		layer, err = NewLayer(rows, cols)
+	}
+	if err != nil {
+		return &Layer{}, i, err
+	}
+	layer.weightsInit = Initializer{}
+	layer.biasInit = Initializer{}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if transposed {
+				layer.weights.data[r][c] = tensor.values[r*cols+c]
+			} else {
+				layer.weights.data[c][r] = tensor.values[r*cols+c]
+			}
+		}
+	}
+
+	biasNames := []string{}
+	if node.opType == "Gemm" && len(node.inputs) > 2 && node.inputs[2] != "" {
+		biasNames = append(biasNames, node.inputs[2])
+	}
+	next := i + 1
+	if next < len(nodes) && nodes[next].opType == "Add" && len(nodes[next].inputs) == 2 {
+		add := nodes[next]
+		if add.inputs[0] == node.outputs[0] {
+			if _, ok := tensors[add.inputs[1]]; ok {
+				biasNames = append(biasNames, add.inputs[1])
+				next = next + 1
+			}
+		} else if add.inputs[1] == node.outputs[0] {
+			if _, ok := tensors[add.inputs[0]]; ok {
+				biasNames = append(biasNames, add.inputs[0])
+				next = next + 1
+			}
+		}
+	}
+	for _, name := range biasNames {
+		bias, ok := tensors[name]
+		if !ok || len(bias.values) != layer.outputNodes {
+			return &Layer{}, i, ErrONNXUnsupported
+		}
+		for r, value := range bias.values {
+			layer.bias.data[r][0] = layer.bias.data[r][0] + value
+		}
+	}
+	return layer, next, nil
+}
+
+// readONNXActivation reads the activation nodes consuming the current tensor
+// It returns the name of the activation function and the index of the node following them
+func readONNXActivation(nodes []onnxNode, i int, current string, tensors map[string]onnxTensor) (string, int, error) {
+	node := nodes[i]
+	if len(node.inputs) < 1 || node.inputs[0] != current || len(node.outputs) != 1 {
+		return "", i, ErrONNXUnsupported
+	}
+
+	if node.opType == "Gelu" {
+		return GELU, i + 1, nil
+	}
+	if node.opType == "Div" {
+		next, err := readONNXGELU(nodes, i, current, tensors)
+		if err != nil {
+			return "", i, err
+		}
+		return GELU, next, nil
+	}
+
+	for name, opType := range onnxActivations {
+		if opType != node.opType {
+			continue
+		}
+		switch name {
+		case LeakyReLU:
+			if alpha, ok := node.floats["alpha"]; ok && math.Abs(alpha-leakyReLUSlope) > 1e-6 {
+				return "", i, ErrONNXUnsupported
+			}
+		case ELU:
+			if alpha, ok := node.floats["alpha"]; ok && math.Abs(alpha-eluAlpha) > 1e-6 {
+				return "", i, ErrONNXUnsupported
+			}
+		case Softmax:
+			if axis, ok := node.ints["axis"]; ok && axis != 1 && axis != -1 {
+				return "", i, ErrONNXUnsupported
+			}
+		}
+		return name, i + 1, nil
+	}
+	return "", i, ErrONNXUnsupported
+}
+
+// readONNXGELU reads the Div, Erf, Add, Mul and Mul nodes computing x * (1 + erf(x / sqrt(2))) * 0.5
+// Every node has to consume the output of the one before it and the constants have to be scalars of the right value
+// It returns the index of the node following the chain
+func readONNXGELU(nodes []onnxNode, i int, current string, tensors map[string]onnxTensor) (int, error) {
+	chain := []string{"Div", "Erf", "Add", "Mul", "Mul"}
+	if i+len(chain) > len(nodes) {
+		return i, ErrONNXUnsupported
+	}
+	for k, opType := range chain {
+		node := nodes[i+k]
+		inputs := 2
+		if opType == "Erf" {
+			inputs = 1
+		}
+		if node.opType != opType || len(node.inputs) != inputs || len(node.outputs) != 1 {
+			return i, ErrONNXUnsupported
+		}
+	}
+
+	div, erf, add, mul, half := nodes[i], nodes[i+1], nodes[i+2], nodes[i+3], nodes[i+4]
+	if div.inputs[0] != current || !onnxScalar(tensors, div.inputs[1], math.Sqrt2) {
+		return i, ErrONNXUnsupported
+	}
+	if erf.inputs[0] != div.outputs[0] {
+		return i, ErrONNXUnsupported
+	}
+	if other, ok := onnxOperand(add, erf.outputs[0]); !ok || !onnxScalar(tensors, other, 1) {
+		return i, ErrONNXUnsupported
+	}
+	if other, ok := onnxOperand(mul, add.outputs[0]); !ok || other != current {
+		return i, ErrONNXUnsupported
+	}
+	if other, ok := onnxOperand(half, mul.outputs[0]); !ok || !onnxScalar(tensors, other, 0.5) {
+		return i, ErrONNXUnsupported
+	}
+	return i + len(chain), nil
+}
+
+// onnxOperand returns the other input of a node with two inputs when one of them is the given tensor
+func onnxOperand(node onnxNode, input string) (string, bool) {
+	if node.inputs[0] == input {
+		return node.inputs[1], true
+	}
+	if node.inputs[1] == input {
+		return node.inputs[0], true
+	}
+	return "", false
+}
+
+// onnxScalar returns whether a name is an initializer holding a single value close to the given one
+func onnxScalar(tensors map[string]onnxTensor, name string, value float64) bool {
+	scalar, ok := tensors[name]
+	return ok && len(scalar.values) == 1 && math.Abs(scalar.values[0]-value) <= 1e-6
+}
+
+// ExportONNX writes the classifier as an ONNX model with the classes in its metadata
+// Weights are stored as float32 and the input and output have a dynamic batch dimension N
+func (mlp *Classifier) ExportONNX(w io.Writer) error {
+	return mlp.writeONNX(w, ClassifierModel, mlp.Classes)
+}
+
+// ImportONNXClassifier return a new pointer to the Classifier Class from an ONNX model
+// Only chains of Gemm or MatMul nodes with optional Add and activation nodes can be imported
+func ImportONNXClassifier(r io.Reader) (*Classifier, error) {
+	nn, kind, classes, err := readONNX(r)
+	if err != nil {
+		return &Classifier{}, err
+	}
+	if kind != "" && kind != ClassifierModel {
+		return &Classifier{}, ErrModelKind
+	}
+
+	return &Classifier{
+		nn,
+		classes,
+	}, nil
+}
+
+// ExportONNX writes the regressor as an ONNX model
+// Weights are stored as float32 and the input and output have a dynamic batch dimension N
+func (r *Regressor) ExportONNX(w io.Writer) error {
+	return r.writeONNX(w, RegressorModel, nil)
+}
+
+// ImportONNXRegressor return a new pointer to the Regressor Class from an ONNX model
+// Only chains of Gemm or MatMul nodes with optional Add and activation nodes can be imported
+func ImportONNXRegressor(r io.Reader) (*Regressor, error) {
+	nn, kind, _, err := readONNX(r)
+	if err != nil {
+		return &Regressor{}, err
+	}
+	if kind != "" && kind != RegressorModel {
+		return &Regressor{}, ErrModelKind
+	}
+
+	return &Regressor{
+		nn,
+	}, nil
+}
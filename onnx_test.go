@@ -0,0 +1,173 @@
+package gomlp
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestONNXRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 5, 4, 3}, 3)
+	if err := mlp.SetActivations([]string{GELU, LeakyReLU, ELU, Softmax}); err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := mlp.ExportONNX(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ImportONNXClassifier(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameNetworks(t, loaded.network, narrowed(mlp.network))
+	if !reflect.DeepEqual(loaded.Classes, mlp.Classes) {
+		t.Errorf("got classes %v, want %v", loaded.Classes, mlp.Classes)
+	}
+	for _, row := range data[:20] {
+		want, _ := mlp.PredictProbabilities(row)
+		got, err := loaded.PredictProbabilities(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-5 {
+				t.Fatalf("got outputs %v, want %v", got, want)
+			}
+		}
+	}
+
+	buffer.Reset()
+	if err = mlp.ExportONNX(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ImportONNXRegressor(&buffer); err != ErrModelKind {
+		t.Errorf("got error %v, want %v", err, ErrModelKind)
+	}
+}
+
+func TestProtobufVarints(t *testing.T) {
+	for _, value := range []uint64{0, 1, 127, 128, 300, 1<<32 - 1, 1 << 63, math.MaxUint64} {
+		encoded := appendVarint(nil, value)
+		got, n, err := readVarint(encoded)
+		if err != nil || got != value || n != len(encoded) {
+			t.Errorf("%d: got %d after %d of %d bytes with error %v", value, got, n, len(encoded), err)
+		}
+		if _, _, err = readVarint(encoded[:len(encoded)-1]); err != ErrProtobuf {
+			t.Errorf("%d truncated: got error %v, want %v", value, err, ErrProtobuf)
+		}
+	}
+
+	message := appendStringField(appendVarintField(nil, 1, 150), 2, "gomlp")
+	fields, err := parseProto(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ints, _ := protoInts(fields, 1); len(ints) != 1 || ints[0] != 150 || protoString(fields, 2) != "gomlp" {
+		t.Errorf("got fields %+v", fields)
+	}
+	if _, err = parseProto(message[:len(message)-1]); err != ErrProtobuf {
+		t.Errorf("got error %v, want %v", err, ErrProtobuf)
+	}
+}
+
+// onnxModel returns an ONNX model of a graph reading input and writing output
+func onnxModel(initializers [][]byte, nodes [][]byte) []byte {
+	var graph, model []byte
+	for _, node := range nodes {
+		graph = appendBytesField(graph, 1, node)
+	}
+	for _, initializer := range initializers {
+		graph = appendBytesField(graph, 5, initializer)
+	}
+	graph = appendBytesField(graph, 11, encodeONNXValueInfo(onnxInput, 2))
+	graph = appendBytesField(graph, 12, encodeONNXValueInfo(onnxOutput, 1))
+	return appendBytesField(model, 7, graph)
+}
+
+func TestONNXRejectsMalformedModels(t *testing.T) {
+	weights := encodeONNXTensor("weights", []int64{1, 2}, []float64{1, 2})
+	cases := map[string][]byte{
+		"huge dims":        onnxModel([][]byte{encodeONNXTensor("weights", []int64{1 << 40, 1 << 40}, []float64{1, 2})}, nil),
+		"overflowing dims": onnxModel([][]byte{encodeONNXTensor("weights", []int64{1 << 62, 4, 4}, []float64{1, 2})}, nil),
+		"negative dims":    onnxModel([][]byte{encodeONNXTensor("weights", []int64{-1, -2}, []float64{1, 2})}, nil),
+		"missing values":   onnxModel([][]byte{encodeONNXTensor("weights", []int64{2, 2}, []float64{1, 2})}, nil),
+		"node without outputs": onnxModel([][]byte{weights}, [][]byte{
+			encodeONNXNode("Gemm", "gemm", []string{onnxInput, "weights"}, nil, encodeONNXIntAttribute("transB", 1)),
+		}),
+		"activation without outputs": onnxModel([][]byte{weights}, [][]byte{
+			encodeONNXNode("Gemm", "gemm", []string{onnxInput, "weights"}, []string{"product"}, encodeONNXIntAttribute("transB", 1)),
+			encodeONNXNode("Sigmoid", "sigmoid", []string{"product"}, nil),
+		}),
+	}
+	for name, model := range cases {
+		if _, err := ImportONNXClassifier(bytes.NewReader(model)); err != ErrONNXUnsupported {
+			t.Errorf("%s: got error %v, want %v", name, err, ErrONNXUnsupported)
+		}
+	}
+
+	valid := onnxModel([][]byte{weights}, [][]byte{
+		encodeONNXNode("Gemm", "gemm", []string{onnxInput, "weights"}, []string{onnxOutput}, encodeONNXIntAttribute("transB", 1)),
+	})
+	mlp, err := ImportONNXClassifier(bytes.NewReader(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs, _ := mlp.PredictProbabilities([]float64{3, 4}); len(outputs) != 1 || outputs[0] != 11 {
+		t.Errorf("got outputs %v, want [11]", outputs)
+	}
+}
+
+func TestONNXRejectsMalformedGELU(t *testing.T) {
+	initializers := [][]byte{
+		encodeONNXTensor("weights", []int64{1, 2}, []float64{1, 2}),
+		encodeONNXTensor("sqrt2", []int64{}, []float64{math.Sqrt2}),
+		encodeONNXTensor("one", []int64{}, []float64{1}),
+		encodeONNXTensor("half", []int64{}, []float64{0.5}),
+		encodeONNXTensor("two", []int64{}, []float64{2}),
+	}
+	gemm := encodeONNXNode("Gemm", "gemm", []string{onnxInput, "weights"}, []string{"x"}, encodeONNXIntAttribute("transB", 1))
+	chain := func(replace int, node []byte) []byte {
+		nodes := [][]byte{
+			gemm,
+			encodeONNXNode("Div", "div", []string{"x", "sqrt2"}, []string{"scaled"}),
+			encodeONNXNode("Erf", "erf", []string{"scaled"}, []string{"erf"}),
+			encodeONNXNode("Add", "add", []string{"one", "erf"}, []string{"shifted"}),
+			encodeONNXNode("Mul", "mul", []string{"shifted", "x"}, []string{"product"}),
+			encodeONNXNode("Mul", "half", []string{"half", "product"}, []string{onnxOutput}),
+		}
+		if node != nil {
+			nodes[replace] = node
+		}
+		return onnxModel(initializers, nodes)
+	}
+
+	mlp, err := ImportONNXClassifier(bytes.NewReader(chain(0, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activations := mlp.Activations(); len(activations) != 1 || activations[0] != GELU {
+		t.Errorf("got activations %v, want [%s]", activations, GELU)
+	}
+
+	cases := map[string][]byte{
+		"div with one input":    chain(1, encodeONNXNode("Div", "div", []string{"x"}, []string{"scaled"})),
+		"div by two":            chain(1, encodeONNXNode("Div", "div", []string{"x", "two"}, []string{"scaled"})),
+		"erf of the input":      chain(2, encodeONNXNode("Erf", "erf", []string{"x"}, []string{"erf"})),
+		"add of two":            chain(3, encodeONNXNode("Add", "add", []string{"erf", "two"}, []string{"shifted"})),
+		"add of the input":      chain(3, encodeONNXNode("Add", "add", []string{"x", "one"}, []string{"shifted"})),
+		"mul by a constant":     chain(4, encodeONNXNode("Mul", "mul", []string{"shifted", "one"}, []string{"product"})),
+		"mul by one":            chain(5, encodeONNXNode("Mul", "half", []string{"product", "one"}, []string{onnxOutput})),
+		"mul of the input":      chain(5, encodeONNXNode("Mul", "half", []string{"x", "half"}, []string{onnxOutput})),
+		"mul with three inputs": chain(5, encodeONNXNode("Mul", "half", []string{"product", "half", "half"}, []string{onnxOutput})),
+	}
+	for name, model := range cases {
+		if _, err := ImportONNXClassifier(bytes.NewReader(model)); err != ErrONNXUnsupported {
+			t.Errorf("%s: got error %v, want %v", name, err, ErrONNXUnsupported)
+		}
+	}
+	truncated := onnxModel(initializers, [][]byte{gemm, encodeONNXNode("Div", "div", []string{"x", "sqrt2"}, []string{onnxOutput})})
+	if _, err := ImportONNXClassifier(bytes.NewReader(truncated)); err != ErrONNXUnsupported {
+		t.Errorf("truncated chain: got error %v, want %v", err, ErrONNXUnsupported)
+	}
+}
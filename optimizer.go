@@ -0,0 +1,475 @@
+package gomlp
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+)
+
+// Names of the built in optimizers
+const (
+	SGDOptimizer      = "sgd"
+	MomentumOptimizer = "momentum"
+	NesterovOptimizer = "nesterov"
+	RMSPropOptimizer  = "rmsprop"
+	AdaGradOptimizer  = "adagrad"
+	AdamOptimizer     = "adam"
+	AdamWOptimizer    = "adamw"
+)
+
+// Optimizer updates the parameters of a network from their gradients and keeps a state for every parameter
+type Optimizer interface {
+	// Name returns the name of the optimizer
+	Name() string
+	// Step advances the optimizer by one iteration and is called once before the updates of a training step
+	Step()
+	// Update subtracts the scaled gradients from the parameter with the given index in place
+	Update(parameter int, weights, gradients *Matrix) error
+	// State returns a serializable copy of the hyperparameters and per parameter state
+	State() OptimizerState
+	// SetState restores the hyperparameters and per parameter state
+	SetState(state OptimizerState) error
+}
+
+// OptimizerState is the Data Structure to hold the serializable state of an Optimizer
+type OptimizerState struct {
+	Name            string                         `json:"name"`
+	Hyperparameters map[string]float64             `json:"hyperparameters"`
+	Iterations      int                            `json:"iterations"`
+	Slots           map[string]map[int][][]float64 `json:"slots"`
+}
+
+// SGD is the Data Structure to hold the stochastic gradient descent optimizer with optional momentum
+type SGD struct {
+	name         string
+	learningRate float64
+	momentum     float64
+	nesterov     bool
+	velocity     map[int]*Matrix
+}
+
+// RMSProp is the Data Structure to hold the RMSProp optimizer
+type RMSProp struct {
+	learningRate float64
+	rho          float64
+	epsilon      float64
+	meanSquare   map[int]*Matrix
+}
+
+// AdaGrad is the Data Structure to hold the AdaGrad optimizer
+type AdaGrad struct {
+	learningRate float64
+	epsilon      float64
+	accumulator  map[int]*Matrix
+}
+
+// Adam is the Data Structure to hold the Adam optimizer with optional decoupled weight decay
+type Adam struct {
+	name         string
+	learningRate float64
+	beta1        float64
+	beta2        float64
+	epsilon      float64
+	weightDecay  float64
+	iterations   int
+	firstMoment  map[int]*Matrix
+	secondMoment map[int]*Matrix
+}
+
+// NewSGD return a new pointer to a plain stochastic gradient descent optimizer
+func NewSGD(learningRate float64) *SGD {
+	return &SGD{SGDOptimizer, learningRate, 0, false, make(map[int]*Matrix)}
+}
+
+// NewMomentum return a new pointer to a stochastic gradient descent optimizer with momentum
+func NewMomentum(learningRate, momentum float64) *SGD {
+	return &SGD{MomentumOptimizer, learningRate, momentum, false, make(map[int]*Matrix)}
+}
+
+// NewNesterov return a new pointer to a stochastic gradient descent optimizer with Nesterov momentum
+func NewNesterov(learningRate, momentum float64) *SGD {
+	return &SGD{NesterovOptimizer, learningRate, momentum, true, make(map[int]*Matrix)}
+}
+
+// NewRMSProp return a new pointer to a RMSProp optimizer
+func NewRMSProp(learningRate, rho, epsilon float64) *RMSProp {
+	return &RMSProp{learningRate, rho, epsilon, make(map[int]*Matrix)}
+}
+
+// NewAdaGrad return a new pointer to an AdaGrad optimizer
+func NewAdaGrad(learningRate, epsilon float64) *AdaGrad {
+	return &AdaGrad{learningRate, epsilon, make(map[int]*Matrix)}
+}
+
+// NewAdam return a new pointer to an Adam optimizer
+func NewAdam(learningRate, beta1, beta2, epsilon float64) *Adam {
+	return &Adam{AdamOptimizer, learningRate, beta1, beta2, epsilon, 0, 0, make(map[int]*Matrix), make(map[int]*Matrix)}
+}
+
+// NewAdamW return a new pointer to an Adam optimizer with decoupled weight decay applied to every parameter
+func NewAdamW(learningRate, beta1, beta2, epsilon, weightDecay float64) *Adam {
+	return &Adam{AdamWOptimizer, learningRate, beta1, beta2, epsilon, weightDecay, 0, make(map[int]*Matrix), make(map[int]*Matrix)}
+}
+
+// NewOptimizerFromState return a new built in Optimizer restored from a saved state
+func NewOptimizerFromState(state OptimizerState) (Optimizer, error) {
+	var optimizer Optimizer
+	switch state.Name {
+	case SGDOptimizer:
+		optimizer = NewSGD(0)
+	case MomentumOptimizer:
+		optimizer = NewMomentum(0, 0)
+	case NesterovOptimizer:
+		optimizer = NewNesterov(0, 0)
+	case RMSPropOptimizer:
+		optimizer = NewRMSProp(0, 0, 0)
+	case AdaGradOptimizer:
+		optimizer = NewAdaGrad(0, 0)
+	case AdamOptimizer:
+		optimizer = NewAdam(0, 0, 0, 0)
+	case AdamWOptimizer:
+		optimizer = NewAdamW(0, 0, 0, 0, 0)
+	default:
+		return nil, ErrUnknownOptimizer
+	}
+
+	err := optimizer.SetState(state)
+	if err != nil {
+		return nil, err
+	}
+	return optimizer, nil
+}
+
+// slot returns the state Matrix of a parameter creating it with the shape of the weights if needed
+func slot(slots map[int]*Matrix, parameter int, weights *Matrix) *Matrix {
+	m, ok := slots[parameter]
+	if !ok || m.rows != weights.rows || m.cols != weights.cols {
+		m, _ = NewMatrix(weights.rows, weights.cols)
+		slots[parameter] = m
+	}
+	return m
+}
+
+// checkGradients returns an error if the weights and gradients differ in shape
+func checkGradients(weights, gradients *Matrix) error {
+	if weights.rows != gradients.rows || weights.cols != gradients.cols {
+		return ErrRowColumnDimension
+	}
+	return nil
+}
+
+// exportSlots converts the state matrices of every parameter into slices
+func exportSlots(slots map[int]*Matrix) map[int][][]float64 {
+	exported := make(map[int][][]float64, len(slots))
+	for parameter, m := range slots {
+		exported[parameter] = m.ConvertFromMatrixToArray2D()
+	}
+	return exported
+}
+
+// importSlots converts the slices of every parameter into state matrices
+func importSlots(exported map[int][][]float64) (map[int]*Matrix, error) {
+	slots := make(map[int]*Matrix, len(exported))
+	for parameter, data := range exported {
+		m, err := ConvertFromArray2DToMatrix(data)
+		if err != nil {
+			return slots, err
+		}
+		slots[parameter] = m
+	}
+	return slots, nil
+}
+
+// Name returns the name of the optimizer
+func (o *SGD) Name() string {
+	return o.name
+}
+
+// Step advances the optimizer by one iteration
+func (o *SGD) Step() {}
+
+// Update subtracts the scaled gradients from the parameter with the given index in place
+func (o *SGD) Update(parameter int, weights, gradients *Matrix) error {
+	err := checkGradients(weights, gradients)
+	if err != nil {
+		return err
+	}
+
+	if o.momentum == 0 {
+		for i := 0; i < weights.rows; i++ {
+			for j := 0; j < weights.cols; j++ {
+				weights.data[i][j] = weights.data[i][j] - o.learningRate*gradients.data[i][j]
+			}
+		}
+		return nil
+	}
+
+	velocity := slot(o.velocity, parameter, weights)
+	for i := 0; i < weights.rows; i++ {
+		for j := 0; j < weights.cols; j++ {
+			previous := velocity.data[i][j]
+			velocity.data[i][j] = o.momentum*previous - o.learningRate*gradients.data[i][j]
+			if o.nesterov {
+				weights.data[i][j] = weights.data[i][j] - o.momentum*previous + (1+o.momentum)*velocity.data[i][j]
+			} else {
+				weights.data[i][j] = weights.data[i][j] + velocity.data[i][j]
+			}
+		}
+	}
+	return nil
+}
+
+// State returns a serializable copy of the hyperparameters and velocities
+func (o *SGD) State() OptimizerState {
+	return OptimizerState{
+		Name: o.name,
+		Hyperparameters: map[string]float64{
+			"learning_rate": o.learningRate,
+			"momentum":      o.momentum,
+		},
+		Slots: map[string]map[int][][]float64{
+			"velocity": exportSlots(o.velocity),
+		},
+	}
+}
+
+// SetState restores the hyperparameters and velocities
+func (o *SGD) SetState(state OptimizerState) error {
+	if state.Name != o.name {
+		return ErrOptimizerState
+	}
+	velocity, err := importSlots(state.Slots["velocity"])
+	if err != nil {
+		return err
+	}
+	o.learningRate = state.Hyperparameters["learning_rate"]
+	o.momentum = state.Hyperparameters["momentum"]
+	o.velocity = velocity
+	return nil
+}
+
+// Name returns the name of the optimizer
+func (o *RMSProp) Name() string {
+	return RMSPropOptimizer
+}
+
+// Step advances the optimizer by one iteration
+func (o *RMSProp) Step() {}
+
+// Update subtracts the scaled gradients from the parameter with the given index in place
+func (o *RMSProp) Update(parameter int, weights, gradients *Matrix) error {
+	err := checkGradients(weights, gradients)
+	if err != nil {
+		return err
+	}
+
+	meanSquare := slot(o.meanSquare, parameter, weights)
+	for i := 0; i < weights.rows; i++ {
+		for j := 0; j < weights.cols; j++ {
+			gradient := gradients.data[i][j]
+			meanSquare.data[i][j] = o.rho*meanSquare.data[i][j] + (1-o.rho)*gradient*gradient
+			weights.data[i][j] = weights.data[i][j] - o.learningRate*gradient/(math.Sqrt(meanSquare.data[i][j])+o.epsilon)
+		}
+	}
+	return nil
+}
+
+// State returns a serializable copy of the hyperparameters and mean squares
+func (o *RMSProp) State() OptimizerState {
+	return OptimizerState{
+		Name: RMSPropOptimizer,
+		Hyperparameters: map[string]float64{
+			"learning_rate": o.learningRate,
+			"rho":           o.rho,
+			"epsilon":       o.epsilon,
+		},
+		Slots: map[string]map[int][][]float64{
+			"mean_square": exportSlots(o.meanSquare),
+		},
+	}
+}
+
+// SetState restores the hyperparameters and mean squares
+func (o *RMSProp) SetState(state OptimizerState) error {
+	if state.Name != RMSPropOptimizer {
+		return ErrOptimizerState
+	}
+	meanSquare, err := importSlots(state.Slots["mean_square"])
+	if err != nil {
+		return err
+	}
+	o.learningRate = state.Hyperparameters["learning_rate"]
+	o.rho = state.Hyperparameters["rho"]
+	o.epsilon = state.Hyperparameters["epsilon"]
+	o.meanSquare = meanSquare
+	return nil
+}
+
+// Name returns the name of the optimizer
+func (o *AdaGrad) Name() string {
+	return AdaGradOptimizer
+}
+
+// Step advances the optimizer by one iteration
+func (o *AdaGrad) Step() {}
+
+// Update subtracts the scaled gradients from the parameter with the given index in place
+func (o *AdaGrad) Update(parameter int, weights, gradients *Matrix) error {
+	err := checkGradients(weights, gradients)
+	if err != nil {
+		return err
+	}
+
+	accumulator := slot(o.accumulator, parameter, weights)
+	for i := 0; i < weights.rows; i++ {
+		for j := 0; j < weights.cols; j++ {
+			gradient := gradients.data[i][j]
+			accumulator.data[i][j] = accumulator.data[i][j] + gradient*gradient
+			weights.data[i][j] = weights.data[i][j] - o.learningRate*gradient/(math.Sqrt(accumulator.data[i][j])+o.epsilon)
+		}
+	}
+	return nil
+}
+
+// State returns a serializable copy of the hyperparameters and accumulated squares
+func (o *AdaGrad) State() OptimizerState {
+	return OptimizerState{
+		Name: AdaGradOptimizer,
+		Hyperparameters: map[string]float64{
+			"learning_rate": o.learningRate,
+			"epsilon":       o.epsilon,
+		},
+		Slots: map[string]map[int][][]float64{
+			"accumulator": exportSlots(o.accumulator),
+		},
+	}
+}
+
+// SetState restores the hyperparameters and accumulated squares
+func (o *AdaGrad) SetState(state OptimizerState) error {
+	if state.Name != AdaGradOptimizer {
+		return ErrOptimizerState
+	}
+	accumulator, err := importSlots(state.Slots["accumulator"])
+	if err != nil {
+		return err
+	}
+	o.learningRate = state.Hyperparameters["learning_rate"]
+	o.epsilon = state.Hyperparameters["epsilon"]
+	o.accumulator = accumulator
+	return nil
+}
+
+// Name returns the name of the optimizer
+func (o *Adam) Name() string {
+	return o.name
+}
+
+// Step advances the optimizer by one iteration used for the bias correction of the moments
+func (o *Adam) Step() {
+	o.iterations = o.iterations + 1
+}
+
+// Update subtracts the scaled gradients from the parameter with the given index in place
+func (o *Adam) Update(parameter int, weights, gradients *Matrix) error {
+	err := checkGradients(weights, gradients)
+	if err != nil {
+		return err
+	}
+
+	iterations := math.Max(float64(o.iterations), 1)
+	firstCorrection := 1 - math.Pow(o.beta1, iterations)
+	secondCorrection := 1 - math.Pow(o.beta2, iterations)
+	firstMoment := slot(o.firstMoment, parameter, weights)
+	secondMoment := slot(o.secondMoment, parameter, weights)
+	for i := 0; i < weights.rows; i++ {
+		for j := 0; j < weights.cols; j++ {
+			gradient := gradients.data[i][j]
+			firstMoment.data[i][j] = o.beta1*firstMoment.data[i][j] + (1-o.beta1)*gradient
+			secondMoment.data[i][j] = o.beta2*secondMoment.data[i][j] + (1-o.beta2)*gradient*gradient
+			first := firstMoment.data[i][j] / firstCorrection
+			second := secondMoment.data[i][j] / secondCorrection
+			weights.data[i][j] = weights.data[i][j] - o.learningRate*(first/(math.Sqrt(second)+o.epsilon)+o.weightDecay*weights.data[i][j])
+		}
+	}
+	return nil
+}
+
+// State returns a serializable copy of the hyperparameters and moments
+func (o *Adam) State() OptimizerState {
+	return OptimizerState{
+		Name: o.name,
+		Hyperparameters: map[string]float64{
+			"learning_rate": o.learningRate,
+			"beta1":         o.beta1,
+			"beta2":         o.beta2,
+			"epsilon":       o.epsilon,
+			"weight_decay":  o.weightDecay,
+		},
+		Iterations: o.iterations,
+		Slots: map[string]map[int][][]float64{
+			"first_moment":  exportSlots(o.firstMoment),
+			"second_moment": exportSlots(o.secondMoment),
+		},
+	}
+}
+
+// SetState restores the hyperparameters and moments
+func (o *Adam) SetState(state OptimizerState) error {
+	if state.Name != o.name {
+		return ErrOptimizerState
+	}
+	firstMoment, err := importSlots(state.Slots["first_moment"])
+	if err != nil {
+		return err
+	}
+	secondMoment, err := importSlots(state.Slots["second_moment"])
+	if err != nil {
+		return err
+	}
+	o.learningRate = state.Hyperparameters["learning_rate"]
+	o.beta1 = state.Hyperparameters["beta1"]
+	o.beta2 = state.Hyperparameters["beta2"]
+	o.epsilon = state.Hyperparameters["epsilon"]
+	o.weightDecay = state.Hyperparameters["weight_decay"]
+	o.iterations = state.Iterations
+	o.firstMoment = firstMoment
+	o.secondMoment = secondMoment
+	return nil
+}
+
+// WriteOptimizerState writes the state of the optimizer of the network to a JSON file
+func (nn *network) WriteOptimizerState(filename string) error {
+	return writeFile(filename, nn.writeOptimizerState)
+}
+
+// writeOptimizerState writes the state of the optimizer of the network as JSON to a writer
+func (nn *network) writeOptimizerState(w io.Writer) error {
+	return json.NewEncoder(w).Encode(nn.optimizer.State())
+}
+
+// ReadOptimizerState restores the optimizer of the network from a JSON file
+func (nn *network) ReadOptimizerState(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var state OptimizerState
+	err = json.NewDecoder(file).Decode(&state)
+	if err != nil {
+		return err
+	}
+
+	if state.Name != nn.optimizer.Name() {
+		optimizer, err := NewOptimizerFromState(state)
+		if err != nil {
+			return err
+		}
+		nn.optimizer = optimizer
+		return nil
+	}
+	return nn.optimizer.SetState(state)
+}
@@ -0,0 +1,84 @@
+package gomlp
+
+import (
+	"math"
+	"testing"
+)
+
+// descend runs an optimizer on the squared distance of two parameters from three and returns them
+func descend(t *testing.T, optimizer Optimizer, weights []*Matrix, steps int) {
+	t.Helper()
+	for s := 0; s < steps; s++ {
+		optimizer.Step()
+		for parameter, w := range weights {
+			gradients := Map(w, func(x float64) float64 { return 2 * (x - 3) })
+			if err := optimizer.Update(parameter, w, gradients); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestOptimizersConverge(t *testing.T) {
+	optimizers := []Optimizer{
+		NewSGD(0.1),
+		NewMomentum(0.05, 0.9),
+		NewNesterov(0.05, 0.9),
+		NewRMSProp(0.01, 0.9, 1e-8),
+		NewAdaGrad(0.5, 1e-8),
+		NewAdam(0.05, 0.9, 0.999, 1e-8),
+		NewAdamW(0.05, 0.9, 0.999, 1e-8, 0),
+	}
+	for _, optimizer := range optimizers {
+		first, _ := ConvertFromArray2DToMatrix([][]float64{{0, -1}, {5, 10}})
+		second, _ := ConvertFromArrayToMatrix1D([]float64{1, 2})
+		descend(t, optimizer, []*Matrix{first, second}, 2000)
+		for _, m := range []*Matrix{first, second} {
+			for _, row := range m.data {
+				for _, x := range row {
+					if math.Abs(x-3) > 1e-2 {
+						t.Errorf("%s: got %v, want 3", optimizer.Name(), m.data)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOptimizerStateRestoresTraining(t *testing.T) {
+	for _, optimizer := range []Optimizer{NewMomentum(0.01, 0.9), NewRMSProp(0.01, 0.9, 1e-8), NewAdaGrad(0.1, 1e-8), NewAdamW(0.01, 0.9, 0.999, 1e-8, 0.01)} {
+		w, _ := ConvertFromArray2DToMatrix([][]float64{{0, -1}, {5, 10}})
+		descend(t, optimizer, []*Matrix{w}, 10)
+
+		restored, err := NewOptimizerFromState(optimizer.State())
+		if err != nil {
+			t.Fatal(err)
+		}
+		copied := w.Copy()
+		descend(t, optimizer, []*Matrix{w}, 10)
+		descend(t, restored, []*Matrix{copied}, 10)
+		for r := range w.data {
+			for c := range w.data[r] {
+				if w.data[r][c] != copied.data[r][c] {
+					t.Fatalf("%s: got %v after restoring, want %v", optimizer.Name(), copied.data, w.data)
+				}
+			}
+		}
+	}
+	if _, err := NewOptimizerFromState(OptimizerState{Name: "lbfgs"}); err != ErrUnknownOptimizer {
+		t.Errorf("got error %v, want %v", err, ErrUnknownOptimizer)
+	}
+}
+
+func TestAdamFirstStepIsTheLearningRate(t *testing.T) {
+	adam := NewAdam(0.1, 0.9, 0.999, 1e-12)
+	w, _ := ConvertFromArrayToMatrix1D([]float64{1, -1})
+	gradients, _ := ConvertFromArrayToMatrix1D([]float64{20, -0.001})
+	adam.Step()
+	if err := adam.Update(0, w, gradients); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(w.data[0][0]-0.9) > 1e-9 || math.Abs(w.data[1][0]+0.9) > 1e-6 {
+		t.Errorf("got %v, want [0.9 -0.9]", w.data)
+	}
+}
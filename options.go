@@ -0,0 +1,75 @@
+package gomlp
+
+import "math/rand"
+
+// Option is used to configure the network of a model when it is constructed
+type Option func(*network) error
+
+// WithOptimizer sets the optimizer used to update the weights during training
+func WithOptimizer(optimizer Optimizer) Option {
+	return func(nn *network) error {
+		return nn.SetOptimizer(optimizer)
+	}
+}
+
+// WithBatchSize sets the number of samples averaged into every weight update
+func WithBatchSize(batchSize int) Option {
+	return func(nn *network) error {
+		return nn.SetBatchSize(batchSize)
+	}
+}
+
+// WithSampler sets the sampler deciding the order of the rows in every epoch
+func WithSampler(sampler Sampler) Option {
+	return func(nn *network) error {
+		return nn.SetSampler(sampler)
+	}
+}
+
+// WithSeed seeds the source of randomness so that training runs with the same seed and data are identical
+func WithSeed(seed int64) Option {
+	return func(nn *network) error {
+		return nn.SetRandomSource(rand.NewSource(seed))
+	}
+}
+
+// WithRandomSource sets the source of randomness used for weight initialization and sampling
+func WithRandomSource(source rand.Source) Option {
+	return func(nn *network) error {
+		return nn.SetRandomSource(source)
+	}
+}
+
+// WithInitializer sets the initialization schemes of the weights and the biases of every layer
+func WithInitializer(weightsInit, biasInit Initializer) Option {
+	return func(nn *network) error {
+		for i := range nn.layers {
+			err := nn.setInitializer(i, weightsInit, biasInit)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithLayerInitializer sets the initialization schemes of the weights and the bias of a single layer
+func WithLayerInitializer(layer int, weightsInit, biasInit Initializer) Option {
+	return func(nn *network) error {
+		return nn.setInitializer(layer, weightsInit, biasInit)
+	}
+}
+
+// WithCheckpoints saves the model into a new sub directory of a directory every given number of epochs
+func WithCheckpoints(dir string, every int) Option {
+	return func(nn *network) error {
+		return nn.SetCheckpoints(dir, every)
+	}
+}
+
+// WithQuantization trains and evaluates the network with weights and activations fake quantized to fixed point
+func WithQuantization(config *FixedPointConfig) Option {
+	return func(nn *network) error {
+		return nn.SetQuantization(config)
+	}
+}
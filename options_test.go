@@ -0,0 +1,35 @@
+package gomlp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSeededTrainingIsReproducible(t *testing.T) {
+	first, _, _ := testClassifier(t, []int{2, 8, 3}, 5)
+	second, _, _ := testClassifier(t, []int{2, 8, 3}, 5)
+	sameNetworks(t, second.network, first.network)
+}
+
+func TestRandomSourceMatchesSeed(t *testing.T) {
+	data, target := testData(40)
+	var trained []*Classifier
+	for _, option := range []Option{WithSeed(5), WithRandomSource(rand.NewSource(5)), WithSeed(6)} {
+		mlp, err := NewClassifierWithLayers([]int{2, 6, 3}, option, WithBatchSize(4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = mlp.Train(data, target, 3); err != nil {
+			t.Fatal(err)
+		}
+		trained = append(trained, mlp)
+	}
+	sameNetworks(t, trained[1].network, trained[0].network)
+	if trained[2].layers[0].weights.data[0][0] == trained[0].layers[0].weights.data[0][0] {
+		t.Errorf("got the same first weight %v for different seeds", trained[0].layers[0].weights.data[0][0])
+	}
+
+	if _, err := NewClassifierWithLayers([]int{2, 6, 3}, WithRandomSource(nil)); err != ErrRandomSource {
+		t.Errorf("got error %v, want %v", err, ErrRandomSource)
+	}
+}
@@ -0,0 +1,172 @@
+package gomlp
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Wire types of the protocol buffer encoding
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoField is the Data Structure to hold a single decoded protocol buffer field
+type protoField struct {
+	number int
+	wire   int
+	value  uint64
+	data   []byte
+}
+
+// appendVarint appends a base 128 varint to a buffer
+func appendVarint(buffer []byte, value uint64) []byte {
+	for value >= 0x80 {
+		buffer = append(buffer, byte(value)|0x80)
+		value = value >> 7
+	}
+	return append(buffer, byte(value))
+}
+
+// appendTag appends the key of a field to a buffer
+func appendTag(buffer []byte, number, wire int) []byte {
+	return appendVarint(buffer, uint64(number)<<3|uint64(wire))
+}
+
+// appendVarintField appends an integer field to a buffer
+func appendVarintField(buffer []byte, number int, value uint64) []byte {
+	buffer = appendTag(buffer, number, protoVarint)
+	return appendVarint(buffer, value)
+}
+
+// appendBytesField appends a length delimited field to a buffer
+func appendBytesField(buffer []byte, number int, data []byte) []byte {
+	buffer = appendTag(buffer, number, protoBytes)
+	buffer = appendVarint(buffer, uint64(len(data)))
+	return append(buffer, data...)
+}
+
+// appendStringField appends a string field to a buffer
+func appendStringField(buffer []byte, number int, value string) []byte {
+	return appendBytesField(buffer, number, []byte(value))
+}
+
+// appendFloat32Field appends a float field to a buffer
+func appendFloat32Field(buffer []byte, number int, value float32) []byte {
+	buffer = appendTag(buffer, number, protoFixed32)
+	return binary.LittleEndian.AppendUint32(buffer, math.Float32bits(value))
+}
+
+// readVarint reads a base 128 varint and returns it with the number of bytes read
+func readVarint(data []byte) (uint64, int, error) {
+	var value uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		value = value | uint64(data[i]&0x7f)<<(7*uint(i))
+		if data[i] < 0x80 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, ErrProtobuf
+}
+
+// parseProto decodes the fields of a protocol buffer message in the order they appear
+func parseProto(data []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(data) > 0 {
+		key, n, err := readVarint(data)
+		if err != nil {
+			return fields, err
+		}
+		data = data[n:]
+
+		field := protoField{number: int(key >> 3), wire: int(key & 7)}
+		switch field.wire {
+		case protoVarint:
+			field.value, n, err = readVarint(data)
+			if err != nil {
+				return fields, err
+			}
+		case protoFixed64:
+			if len(data) < 8 {
+				return fields, ErrProtobuf
+			}
+			field.value = binary.LittleEndian.Uint64(data)
+			n = 8
+		case protoFixed32:
+			if len(data) < 4 {
+				return fields, ErrProtobuf
+			}
+			field.value = uint64(binary.LittleEndian.Uint32(data))
+			n = 4
+		case protoBytes:
+			length, m, err := readVarint(data)
+			if err != nil || length > uint64(len(data)-m) {
+				return fields, ErrProtobuf
+			}
+			field.data = data[m : m+int(length)]
+			n = m + int(length)
+		default:
+			return fields, ErrProtobuf
+		}
+		data = data[n:]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// protoInts returns the integers of a repeated field whether they were written packed or one per field
+func protoInts(fields []protoField, number int) ([]int64, error) {
+	var values []int64
+	for _, field := range fields {
+		if field.number != number {
+			continue
+		}
+		if field.wire == protoVarint {
+			values = append(values, int64(field.value))
+			continue
+		}
+		data := field.data
+		for len(data) > 0 {
+			value, n, err := readVarint(data)
+			if err != nil {
+				return values, err
+			}
+			values = append(values, int64(value))
+			data = data[n:]
+		}
+	}
+	return values, nil
+}
+
+// protoStrings returns the values of a repeated string field
+func protoStrings(fields []protoField, number int) []string {
+	var values []string
+	for _, field := range fields {
+		if field.number == number && field.wire == protoBytes {
+			values = append(values, string(field.data))
+		}
+	}
+	return values
+}
+
+// protoMessages returns the contents of a repeated message field
+func protoMessages(fields []protoField, number int) [][]byte {
+	var values [][]byte
+	for _, field := range fields {
+		if field.number == number && field.wire == protoBytes {
+			values = append(values, field.data)
+		}
+	}
+	return values
+}
+
+// protoString returns the last value of a string field or an empty string
+func protoString(fields []protoField, number int) string {
+	values := protoStrings(fields, number)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
@@ -0,0 +1,311 @@
+package gomlp
+
+import (
+	"math"
+	"sort"
+)
+
+// Masks returns copies of the pruning masks of the weights of every layer or nil when nothing is pruned
+// Masked weights hold one where the weight is kept and zero where it is pruned
+func (nn *network) Masks() []*Matrix {
+	if nn.masks == nil {
+		return nil
+	}
+	masks := make([]*Matrix, len(nn.masks))
+	for i, mask := range nn.masks {
+		masks[i] = mask.Copy()
+	}
+	return masks
+}
+
+// ClearMasks lets every pruned weight train again
+func (nn *network) ClearMasks() {
+	nn.masks = nil
+}
+
+// Sparsity returns the share of the weights of all layers that are pruned
+func (nn *network) Sparsity() float64 {
+	var pruned, total int
+	for i, layer := range nn.layers {
+		total = total + layer.inputNodes*layer.outputNodes
+		if nn.masks == nil {
+			continue
+		}
+		for _, row := range nn.masks[i].data {
+			for _, keep := range row {
+				if keep == 0 {
+					pruned = pruned + 1
+				}
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(pruned) / float64(total)
+}
+
+// ensureMasks creates masks keeping every weight when there are none
+func (nn *network) ensureMasks() {
+	if nn.masks != nil {
+		return
+	}
+	nn.masks = make([]*Matrix, len(nn.layers))
+	for i, layer := range nn.layers {
+		nn.masks[i] = Map(layer.weights, func(float64) float64 {
+			return 1
+		})
+	}
+}
+
+// masksFromZeros creates masks pruning every weight that is exactly zero
+func (nn *network) masksFromZeros() {
+	nn.masks = make([]*Matrix, len(nn.layers))
+	for i, layer := range nn.layers {
+		nn.masks[i] = Map(layer.weights, func(x float64) float64 {
+			if x == 0 {
+				return 0
+			}
+			return 1
+		})
+	}
+}
+
+// applyMasks sets every pruned weight to zero
+func (nn *network) applyMasks() {
+	if nn.masks == nil {
+		return
+	}
+	for i, layer := range nn.layers {
+		for r, row := range nn.masks[i].data {
+			for c, keep := range row {
+				if keep == 0 {
+					layer.weights.data[r][c] = 0
+				}
+			}
+		}
+	}
+}
+
+// pruneSmallest prunes the weights of some layers with the smallest magnitudes until a share of them is pruned
+// Weights that are already pruned count toward the share
+func (nn *network) pruneSmallest(layers []int, sparsity float64) error {
+	if sparsity < 0 || sparsity > 1 || math.IsNaN(sparsity) {
+		return ErrSparsity
+	}
+	nn.ensureMasks()
+
+	type position struct {
+		layer, row, col int
+		magnitude       float64
+	}
+	var kept []position
+	var total, pruned int
+	for _, i := range layers {
+		for r, row := range nn.layers[i].weights.data {
+			for c, weight := range row {
+				total = total + 1
+				if nn.masks[i].data[r][c] == 0 {
+					pruned = pruned + 1
+					continue
+				}
+				kept = append(kept, position{i, r, c, math.Abs(weight)})
+			}
+		}
+	}
+
+	target := int(math.Round(sparsity * float64(total)))
+	if target <= pruned {
+		return nil
+	}
+	sort.SliceStable(kept, func(a, b int) bool {
+		return kept[a].magnitude < kept[b].magnitude
+	})
+	for _, p := range kept[:target-pruned] {
+		nn.masks[p.layer].data[p.row][p.col] = 0
+	}
+	nn.applyMasks()
+	return nil
+}
+
+// PruneGlobal prunes the weights with the smallest magnitudes across all layers until a share of them is pruned
+func (nn *network) PruneGlobal(sparsity float64) error {
+	layers := make([]int, len(nn.layers))
+	for i := range layers {
+		layers[i] = i
+	}
+	return nn.pruneSmallest(layers, sparsity)
+}
+
+// PruneLayers prunes the weights with the smallest magnitudes in every layer until a share of each layer is pruned
+func (nn *network) PruneLayers(sparsity float64) error {
+	for i := range nn.layers {
+		err := nn.PruneLayer(i, sparsity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneLayer prunes the weights with the smallest magnitudes in a layer until a share of the layer is pruned
+func (nn *network) PruneLayer(layer int, sparsity float64) error {
+	if layer < 0 || layer >= len(nn.layers) {
+		return ErrLayerIndex
+	}
+	return nn.pruneSmallest([]int{layer}, sparsity)
+}
+
+// PruneNeurons removes the hidden neurons with the smallest outgoing weights from the outputs of a layer
+// The rows of the layer and the columns of the next layer belonging to the neurons are removed so the
+// network shrinks while masks and optimizer state of the other weights are kept
+func (nn *network) PruneNeurons(layer, count int) error {
+	if layer < 0 || layer >= len(nn.layers)-1 {
+		return ErrLayerIndex
+	}
+	current, next := nn.layers[layer], nn.layers[layer+1]
+	if count < 0 || count >= current.outputNodes {
+		return ErrPruneNeurons
+	}
+	if count == 0 {
+		return nil
+	}
+
+	norms := make([]float64, current.outputNodes)
+	for _, row := range next.weights.data {
+		for j, weight := range row {
+			norms[j] = norms[j] + weight*weight
+		}
+	}
+	order := make([]int, len(norms))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return norms[order[a]] < norms[order[b]]
+	})
+	removed := make(map[int]bool, count)
+	for _, j := range order[:count] {
+		removed[j] = true
+	}
+
+	keepRowsOf := func(data [][]float64) [][]float64 {
+		var rows [][]float64
+		for j, row := range data {
+			if !removed[j] {
+				rows = append(rows, row)
+			}
+		}
+		return rows
+	}
+	keepColsOf := func(data [][]float64) [][]float64 {
+		rows := make([][]float64, len(data))
+		for r, row := range data {
+			for j, value := range row {
+				if !removed[j] {
+					rows[r] = append(rows[r], value)
+				}
+			}
+		}
+		return rows
+	}
+	keepRows := func(m *Matrix) *Matrix {
+		shrunk, _ := ConvertFromArray2DToMatrix(keepRowsOf(m.data))
+		return shrunk
+	}
+	keepCols := func(m *Matrix) *Matrix {
+		shrunk, _ := ConvertFromArray2DToMatrix(keepColsOf(m.data))
+		return shrunk
+	}
+
+	if nn.optimizer != nil {
+		// the state of the weights and biases of the layer and the weights of the next layer shrinks with them
+		state := nn.optimizer.State()
+		shapes := map[int][2]int{
+			2 * layer:       {current.weights.rows, current.weights.cols},
+			2*layer + 1:     {current.bias.rows, current.bias.cols},
+			2 * (layer + 1): {next.weights.rows, next.weights.cols},
+		}
+		for _, slots := range state.Slots {
+			for parameter, shape := range shapes {
+				data, ok := slots[parameter]
+				if !ok || len(data) != shape[0] || len(data[0]) != shape[1] {
+					continue
+				}
+				if parameter == 2*(layer+1) {
+					slots[parameter] = keepColsOf(data)
+				} else {
+					slots[parameter] = keepRowsOf(data)
+				}
+			}
+		}
+		err := nn.optimizer.SetState(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	current.weights = keepRows(current.weights)
+	current.bias = keepRows(current.bias)
+	current.outputNodes = current.outputNodes - count
+	next.weights = keepCols(next.weights)
+	next.inputNodes = next.inputNodes - count
+	nn.layerSizes[layer+1] = current.outputNodes
+	if nn.masks != nil {
+		nn.masks[layer] = keepRows(nn.masks[layer])
+		nn.masks[layer+1] = keepCols(nn.masks[layer+1])
+	}
+	return nil
+}
+
+// PruningSchedule returns the sparsity of every step of an iterative pruning schedule
+// The sparsity rises quickly at first and slowly toward the final sparsity following a cubic curve
+func PruningSchedule(sparsity float64, steps int) ([]float64, error) {
+	if sparsity < 0 || sparsity > 1 || math.IsNaN(sparsity) {
+		return []float64{}, ErrSparsity
+	}
+	if steps < 1 {
+		return []float64{}, ErrPruneSchedule
+	}
+
+	schedule := make([]float64, steps)
+	for i := range schedule {
+		progress := float64(i+1) / float64(steps)
+		schedule[i] = sparsity * (1 - math.Pow(1-progress, 3))
+	}
+	return schedule, nil
+}
+
+// pruneAndRetrain prunes the network globally to every sparsity of a schedule retraining it after every step
+func (nn *network) pruneAndRetrain(schedule []float64, retrain func() error) error {
+	if len(schedule) == 0 {
+		return ErrPruneSchedule
+	}
+	for _, sparsity := range schedule {
+		err := nn.PruneGlobal(sparsity)
+		if err != nil {
+			return err
+		}
+		err = retrain()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneAndRetrain prunes the classifier globally to every sparsity of a schedule and trains it for a number
+// of epochs after every step with the pruned weights held at zero
+func (mlp *Classifier) PruneAndRetrain(data, targetArr [][]float64, schedule []float64, epochs int) error {
+	return mlp.pruneAndRetrain(schedule, func() error {
+		return mlp.Train(data, targetArr, epochs)
+	})
+}
+
+// PruneAndRetrain prunes the regressor globally to every sparsity of a schedule and trains it for a number
+// of epochs after every step with the pruned weights held at zero
+func (r *Regressor) PruneAndRetrain(data, targetArr [][]float64, schedule []float64, epochs int) error {
+	return r.pruneAndRetrain(schedule, func() error {
+		return r.Train(data, targetArr, epochs)
+	})
+}
@@ -0,0 +1,125 @@
+package gomlp
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPruneGlobalKeepsWeightsAtZeroThroughTraining(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 3}, 10)
+	schedule, err := PruningSchedule(0.5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule[2] != 0.5 || schedule[0] <= 0 || schedule[0] >= schedule[1] {
+		t.Errorf("got schedule %v", schedule)
+	}
+	if err = mlp.PruneAndRetrain(data, target, schedule, 3); err != nil {
+		t.Fatal(err)
+	}
+	if sparsity := mlp.Sparsity(); math.Abs(sparsity-0.5) > 0.01 {
+		t.Errorf("got sparsity %v, want 0.5", sparsity)
+	}
+	for i, mask := range mlp.Masks() {
+		for r, row := range mask.data {
+			for c, keep := range row {
+				if keep == 0 && mlp.layers[i].weights.data[r][c] != 0 {
+					t.Fatalf("layer %d [%d][%d]: pruned weight trained to %v", i, r, c, mlp.layers[i].weights.data[r][c])
+				}
+			}
+		}
+	}
+
+	// a loaded model prunes the weights saved as zeros
+	var buffer bytes.Buffer
+	if err = mlp.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadClassifier(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sparsity() != mlp.Sparsity() {
+		t.Errorf("got sparsity %v after loading, want %v", loaded.Sparsity(), mlp.Sparsity())
+	}
+
+	if err = mlp.PruneGlobal(1.5); err != ErrSparsity {
+		t.Errorf("got error %v, want %v", err, ErrSparsity)
+	}
+	if _, err = PruningSchedule(0.5, 0); err != ErrPruneSchedule {
+		t.Errorf("got error %v, want %v", err, ErrPruneSchedule)
+	}
+}
+
+func TestPruneLayersPrunesEveryLayer(t *testing.T) {
+	mlp, _, _ := testClassifier(t, []int{2, 12, 8, 3}, 1)
+	if err := mlp.PruneLayers(0.25); err != nil {
+		t.Fatal(err)
+	}
+	for i, layer := range mlp.layers {
+		zeros := 0
+		for _, row := range layer.weights.data {
+			for _, weight := range row {
+				if weight == 0 {
+					zeros = zeros + 1
+				}
+			}
+		}
+		if want := int(math.Round(0.25 * float64(layer.inputNodes*layer.outputNodes))); zeros != want {
+			t.Errorf("layer %d: got %d pruned weights, want %d", i, zeros, want)
+		}
+	}
+}
+
+func TestPruneNeuronsShrinksTheNetwork(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 3}, 5)
+	if err := mlp.PruneGlobal(0.2); err != nil {
+		t.Fatal(err)
+	}
+	before := mlp.optimizer.State()
+	next := mlp.layers[2].weights.Copy()
+	if err := mlp.PruneNeurons(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if sizes := mlp.LayerSizes(); sizes[2] != 5 {
+		t.Fatalf("got layer sizes %v", sizes)
+	}
+	layer, following := mlp.layers[1], mlp.layers[2]
+	if layer.weights.rows != 5 || layer.bias.rows != 5 || following.weights.cols != 5 || following.inputNodes != 5 {
+		t.Fatalf("got shapes %dx%d and %dx%d", layer.weights.rows, layer.weights.cols, following.weights.rows, following.weights.cols)
+	}
+	if masks := mlp.Masks(); masks[1].rows != 5 || masks[2].cols != 5 {
+		t.Errorf("got masks of %d rows and %d columns", masks[1].rows, masks[2].cols)
+	}
+
+	// the columns of the next layer and their optimizer state are kept in order
+	after := mlp.optimizer.State()
+	for name, slots := range after.Slots {
+		if len(slots[2]) != 5 || len(slots[3]) != 5 || len(slots[4][0]) != 5 {
+			t.Fatalf("%s: slots were not shrunk", name)
+		}
+		kept := 0
+		for c := 0; c < next.cols && kept < 5; c++ {
+			if next.data[0][c] == following.weights.data[0][kept] {
+				if before.Slots[name][4][0][c] != slots[4][0][kept] {
+					t.Fatalf("%s: column %d lost its state", name, c)
+				}
+				kept = kept + 1
+			}
+		}
+		if kept != 5 {
+			t.Fatalf("got %d of 5 kept columns", kept)
+		}
+	}
+	if err := mlp.Train(data, target, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mlp.PruneNeurons(2, 1); err != ErrLayerIndex {
+		t.Errorf("got error %v, want %v", err, ErrLayerIndex)
+	}
+	if err := mlp.PruneNeurons(0, 12); err != ErrPruneNeurons {
+		t.Errorf("got error %v, want %v", err, ErrPruneNeurons)
+	}
+}
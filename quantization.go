@@ -0,0 +1,126 @@
+package gomlp
+
+import "fmt"
+
+// savedQuantization is the Data Structure to hold the quantization of a network in a model file
+type savedQuantization struct {
+	Weights     string `json:"weights"`
+	Activations string `json:"activations"`
+	Accumulator string `json:"accumulator"`
+	Overflow    string `json:"overflow"`
+	Rounding    string `json:"rounding"`
+}
+
+// String returns the format in Q notation such as Q4.12
+func (q QFormat) String() string {
+	return fmt.Sprintf("Q%d.%d", q.integerBits, q.fractionBits)
+}
+
+// ParseQFormat returns the format written in Q notation such as Q4.12
+func ParseQFormat(s string) (QFormat, error) {
+	var integerBits, fractionBits int
+	_, err := fmt.Sscanf(s, "Q%d.%d", &integerBits, &fractionBits)
+	if err != nil || s != fmt.Sprintf("Q%d.%d", integerBits, fractionBits) {
+		return QFormat{}, ErrQFormat
+	}
+	return NewQFormat(integerBits, fractionBits)
+}
+
+// parseOverflowMode returns the overflow mode with a name
+func parseOverflowMode(name string) (OverflowMode, error) {
+	for _, overflow := range []OverflowMode{Saturate, Wrap} {
+		if overflow.String() == name {
+			return overflow, nil
+		}
+	}
+	return Saturate, ErrFixedPointMode
+}
+
+// parseRoundingMode returns the rounding mode with a name
+func parseRoundingMode(name string) (RoundingMode, error) {
+	for _, rounding := range []RoundingMode{Truncate, RoundTowardZero, RoundHalfUp, RoundHalfEven} {
+		if rounding.String() == name {
+			return rounding, nil
+		}
+	}
+	return Truncate, ErrFixedPointMode
+}
+
+// fakeQuantize returns a new Matrix with every element rounded to the nearest value of a format
+func (c *FixedPointConfig) fakeQuantize(m *Matrix, format QFormat) *Matrix {
+	return Map(m, func(x float64) float64 {
+		return format.ToFloat(format.Quantize(x, c.rounding, c.overflow))
+	})
+}
+
+// forward returns the forward pass of a layer with fake quantized weights, biases, sums and outputs
+func (c *FixedPointConfig) forward(l *Layer) func(*Matrix) (*Matrix, *Matrix, error) {
+	return func(input *Matrix) (*Matrix, *Matrix, error) {
+		z, err := Multiply(c.fakeQuantize(l.weights, c.weights), input)
+		if err != nil {
+			return &Matrix{}, &Matrix{}, err
+		}
+		z, err = AddColumnVector(z, c.fakeQuantize(l.bias, c.accumulator))
+		if err != nil {
+			return &Matrix{}, &Matrix{}, err
+		}
+
+		z = c.fakeQuantize(z, c.accumulator)
+		return z, c.fakeQuantize(l.activationFunc.apply(z), c.activations), nil
+	}
+}
+
+// Quantization returns the fixed point formats the network is trained and evaluated with or nil
+func (nn *network) Quantization() *FixedPointConfig {
+	return nn.quantization
+}
+
+// SetQuantization turns on quantization aware training and quantized evaluation in the formats of a config
+// The forward pass of Train, Predict and Score rounds the weights, biases, sums and outputs of every layer to their
+// formats while the backward pass treats the rounding as the identity and updates the float weights
+// A nil config turns quantization off
+func (nn *network) SetQuantization(config *FixedPointConfig) error {
+	if config != nil {
+		_, err := NewFixedPointConfig(config.weights, config.activations, config.accumulator, config.overflow, config.rounding)
+		if err != nil {
+			return err
+		}
+	}
+	nn.quantization = config
+	return nil
+}
+
+// saved returns the quantization for a model file
+func (c *FixedPointConfig) saved() *savedQuantization {
+	if c == nil {
+		return nil
+	}
+	return &savedQuantization{
+		c.weights.String(),
+		c.activations.String(),
+		c.accumulator.String(),
+		c.overflow.String(),
+		c.rounding.String(),
+	}
+}
+
+// newFixedPointConfigFromSaved return a new pointer to the config of a quantization in a model file
+func newFixedPointConfigFromSaved(saved *savedQuantization) (*FixedPointConfig, error) {
+	var formats []QFormat
+	for _, s := range []string{saved.Weights, saved.Activations, saved.Accumulator} {
+		format, err := ParseQFormat(s)
+		if err != nil {
+			return &FixedPointConfig{}, err
+		}
+		formats = append(formats, format)
+	}
+	overflow, err := parseOverflowMode(saved.Overflow)
+	if err != nil {
+		return &FixedPointConfig{}, err
+	}
+	rounding, err := parseRoundingMode(saved.Rounding)
+	if err != nil {
+		return &FixedPointConfig{}, err
+	}
+	return NewFixedPointConfig(formats[0], formats[1], formats[2], overflow, rounding)
+}
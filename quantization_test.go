@@ -0,0 +1,102 @@
+package gomlp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseQFormat(t *testing.T) {
+	q, err := ParseQFormat("Q4.12")
+	if err != nil || q.IntegerBits() != 4 || q.FractionBits() != 12 || q.String() != "Q4.12" {
+		t.Errorf("got %v with error %v", q, err)
+	}
+	for _, s := range []string{"Q4", "4.12", "Q4.12 ", "Q0.8", "Q4.-1"} {
+		if _, err = ParseQFormat(s); err != ErrQFormat {
+			t.Errorf("%q: got error %v, want %v", s, err, ErrQFormat)
+		}
+	}
+}
+
+func TestQuantizationAwareTrainingMatchesFixedPoint(t *testing.T) {
+	mlp, data, target := testClassifier(t, []int{2, 12, 8, 3}, 10)
+	weights, _ := NewQFormat(4, 4)
+	activations, _ := NewQFormat(4, 4)
+	accumulator, _ := NewQFormat(12, 8)
+	config, err := NewFixedPointConfig(weights, activations, accumulator, Saturate, RoundHalfEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.SetQuantization(config); err != nil {
+		t.Fatal(err)
+	}
+	if err = mlp.Train(data, target, 10); err != nil {
+		t.Fatal(err)
+	}
+	score, err := mlp.Score(data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score < 0.8 {
+		t.Errorf("got accuracy %v, want at least 0.8", score)
+	}
+
+	// the accumulator keeps every fraction bit of a product so fake quantization is exact
+	fc, err := NewFixedPointClassifier(mlp, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range data {
+		want, _ := mlp.PredictProbabilities(row)
+		got, err := fc.Outputs(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("got outputs %v, want %v", got, want)
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err = mlp.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadClassifier(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *loaded.Quantization() != *config {
+		t.Errorf("got quantization %+v, want %+v", loaded.Quantization(), config)
+	}
+}
+
+func TestSetQuantizationRejectsInvalidConfigs(t *testing.T) {
+	wide, _ := NewQFormat(31, 10)
+	invalid, err := NewFixedPointConfig(wide, wide, wide, Saturate, RoundHalfEven)
+	if err != ErrQFormat {
+		t.Fatalf("got error %v, want %v", err, ErrQFormat)
+	}
+	q := testFixedPointConfig(t)
+	cases := map[string]*FixedPointConfig{
+		"zero value":       invalid,
+		"too wide":         {wide, wide, q.accumulator, q.overflow, q.rounding},
+		"unknown mode":     {q.weights, q.activations, q.accumulator, OverflowMode(7), q.rounding},
+		"wide accumulator": {q.weights, q.activations, QFormat{4, 40}, q.overflow, q.rounding},
+	}
+	for name, config := range cases {
+		nn, err := newNetwork([]int{2, 3, 1}, WithSeed(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = nn.SetQuantization(config); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+		if nn.Quantization() != nil {
+			t.Errorf("%s: got quantization %v, want nil", name, nn.Quantization())
+		}
+	}
+	if _, err = NewClassifierWithLayers([]int{2, 3, 1}, WithQuantization(invalid)); err != ErrQFormat {
+		t.Errorf("got error %v, want %v", err, ErrQFormat)
+	}
+}
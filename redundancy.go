@@ -0,0 +1,326 @@
+package gomlp
+
+import "math"
+
+// NewTMRClassifier return a new pointer to a classifier holding three copies of the weights of a trained Classifier
+// Predictions read the weights through a bitwise majority vote of the copies taken whenever faults are injected
+func NewTMRClassifier(mlp *Classifier) *TMRClassifier {
+	var copies [3]*Classifier
+	for i := range copies {
+		copies[i] = mlp.clone()
+	}
+	t := &TMRClassifier{copies: copies}
+	t.voted = t.vote()
+	return t
+}
+
+// Copies returns the three copies of the classifier
+func (t *TMRClassifier) Copies() [3]*Classifier {
+	return t.copies
+}
+
+// Detected returns the number of voted words whose copies disagreed since the counters were reset
+// Every vote counts the words it finds corrupted so faults left in place are counted again by the next vote
+func (t *TMRClassifier) Detected() int {
+	return t.detected
+}
+
+// Corrected returns the number of voted words where two copies agreed against the third since the counters were reset
+func (t *TMRClassifier) Corrected() int {
+	return t.corrected
+}
+
+// ResetCounters sets the detected and corrected counters to zero
+func (t *TMRClassifier) ResetCounters() {
+	t.detected = 0
+	t.corrected = 0
+}
+
+// InjectFaults corrupts the weights or biases of each copy independently, votes the copies again and returns the
+// number of flips
+func (t *TMRClassifier) InjectFaults(injector *FaultInjector, target FaultTarget) (int, error) {
+	var flips int
+	for i, replica := range t.copies {
+		corrupted, n, err := replica.InjectFaults(injector, target)
+		if err != nil {
+			t.voted = t.vote()
+			return flips, err
+		}
+		t.copies[i] = corrupted
+		flips = flips + n
+	}
+	t.voted = t.vote()
+	return flips, nil
+}
+
+// voteWord returns the bitwise majority of three words counting disagreements
+func (t *TMRClassifier) voteWord(a, b, c float64) float64 {
+	x, y, z := math.Float64bits(a), math.Float64bits(b), math.Float64bits(c)
+	if x == y && y == z {
+		return a
+	}
+	t.detected = t.detected + 1
+	if x == y || x == z || y == z {
+		t.corrected = t.corrected + 1
+	}
+	return math.Float64frombits((x & y) | (x & z) | (y & z))
+}
+
+// vote returns a classifier with the weights and biases voted from the three copies
+func (t *TMRClassifier) vote() *Classifier {
+	voted := t.copies[0].clone()
+	for i, layer := range voted.layers {
+		for _, pair := range [][4]*Matrix{
+			{layer.weights, t.copies[0].layers[i].weights, t.copies[1].layers[i].weights, t.copies[2].layers[i].weights},
+			{layer.bias, t.copies[0].layers[i].bias, t.copies[1].layers[i].bias, t.copies[2].layers[i].bias},
+		} {
+			for r := range pair[0].data {
+				for c := range pair[0].data[r] {
+					pair[0].data[r][c] = t.voteWord(pair[1].data[r][c], pair[2].data[r][c], pair[3].data[r][c])
+				}
+			}
+		}
+	}
+	return voted
+}
+
+// Predict return the predicted class index of the voted weights in the same way as Classifier.Predict
+func (t *TMRClassifier) Predict(inputArr []float64) (int, error) {
+	return t.voted.Predict(inputArr)
+}
+
+// Score return the accuracy of the voted weights in the same way as Classifier.Score
+func (t *TMRClassifier) Score(data [][]float64, target [][]float64) (float64, error) {
+	return scoreClassifier(t.voted.Predict, data, target)
+}
+
+// faultLayers returns the number of layers exposed to faults
+func (t *TMRClassifier) faultLayers() int {
+	return len(t.copies[0].layers)
+}
+
+// faulty returns a predictor of the voted classifier exposed to faults in a target counting the flips
+// Faults in weights and biases hit each copy independently and faults in activations hit the voted network
+func (t *TMRClassifier) faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error) {
+	if target == FaultActivations {
+		return t.voted.faulty(injector, target, flips)
+	}
+
+	corrupted := &TMRClassifier{copies: t.copies}
+	n, err := corrupted.InjectFaults(injector, target)
+	*flips = *flips + n
+	if err != nil {
+		return nil, err
+	}
+	t.detected = t.detected + corrupted.detected
+	t.corrected = t.corrected + corrupted.corrected
+	return corrupted.voted.Predict, nil
+}
+
+// NewRedundantRNSClassifier return a new pointer to a trained Classifier evaluated in a redundant residue number system
+// The last moduli of the system are redundant and must be larger than every other modulus
+// Sums must stay within the range of the other moduli so one redundant modulus detects a wrong residue and
+// two correct a single wrong residue, so a network whose largest possible sum exceeds that range is rejected
+func NewRedundantRNSClassifier(mlp *Classifier, system *RNS, redundant int, config *FixedPointConfig) (*RedundantRNSClassifier, error) {
+	if system == nil || redundant < 1 || redundant >= len(system.moduli) {
+		return &RedundantRNSClassifier{}, ErrRNSModuli
+	}
+	information := len(system.moduli) - redundant
+	legitimate := int64(1)
+	for i, m := range system.moduli {
+		if i < information {
+			legitimate = legitimate * m
+			continue
+		}
+		for _, other := range system.moduli[:information] {
+			if m <= other {
+				return &RedundantRNSClassifier{}, ErrRNSModuli
+			}
+		}
+	}
+
+	rn, err := newRNSNetwork(mlp.network, system, config, legitimate)
+	if err != nil {
+		return &RedundantRNSClassifier{}, err
+	}
+	for i := range rn.layers {
+		if !rn.sumsWithin(i, (legitimate-1)/2) {
+			return &RedundantRNSClassifier{}, ErrRNSModuli
+		}
+	}
+
+	var subsystems []*RNS
+	if redundant > 1 {
+		subsystems = make([]*RNS, len(system.moduli))
+		for i := range system.moduli {
+			var others []int64
+			for j, m := range system.moduli {
+				if j != i {
+					others = append(others, m)
+				}
+			}
+			subsystems[i], err = NewRNS(others...)
+			if err != nil {
+				return &RedundantRNSClassifier{}, err
+			}
+		}
+	}
+
+	return &RedundantRNSClassifier{
+		&RNSClassifier{rn, append([]float64(nil), mlp.Classes...)},
+		legitimate,
+		subsystems,
+		0,
+		0,
+	}, nil
+}
+
+// sumsWithin returns whether every sum of a layer stays within a bound for any inputs of the activation format
+// The largest sum is the magnitude of the bias plus the magnitudes of the weights times the largest activation
+func (rn *rnsNetwork) sumsWithin(layer int, bound int64) bool {
+	largest := -1 * rn.config.activations.Min()
+	for r, row := range rn.layers[layer].weights {
+		bias, _ := rn.system.Reverse(rn.layers[layer].bias[r])
+		sum := absInt64(bias)
+		for _, residues := range row {
+			weight, _ := rn.system.Reverse(residues)
+			if sum > bound {
+				return false
+			}
+			sum = sum + absInt64(weight)*largest
+		}
+		if sum > bound {
+			return false
+		}
+	}
+	return true
+}
+
+// absInt64 returns the magnitude of an integer
+func absInt64(x int64) int64 {
+	if x < 0 {
+		return -1 * x
+	}
+	return x
+}
+
+// Detected returns the number of sums found out of the legitimate range since the counters were reset
+func (rr *RedundantRNSClassifier) Detected() int {
+	return rr.detected
+}
+
+// Corrected returns the number of sums whose wrong residue was corrected since the counters were reset
+func (rr *RedundantRNSClassifier) Corrected() int {
+	return rr.corrected
+}
+
+// ResetCounters sets the detected and corrected counters to zero
+func (rr *RedundantRNSClassifier) ResetCounters() {
+	rr.detected = 0
+	rr.corrected = 0
+}
+
+// System returns the redundant residue number system of the network
+func (rr *RedundantRNSClassifier) System() *RNS {
+	return rr.rns.system
+}
+
+// isLegitimate returns whether a signed number lies in the range of the information moduli
+func (rr *RedundantRNSClassifier) isLegitimate(x int64) bool {
+	return x >= -1*(rr.legitimate/2) && x <= (rr.legitimate-1)/2
+}
+
+// check detects and corrects the residues of the sums of a layer in place
+func (rr *RedundantRNSClassifier) check(layer int, sums [][]int64) {
+	system := rr.rns.system
+	for _, residues := range sums {
+		x, _ := system.Reverse(residues)
+		if rr.isLegitimate(x) {
+			continue
+		}
+		rr.detected = rr.detected + 1
+		for i, subsystem := range rr.subsystems {
+			reduced := make([]int64, 0, len(residues)-1)
+			for j, residue := range residues {
+				if j != i {
+					reduced = append(reduced, residue)
+				}
+			}
+			value, _ := subsystem.Reverse(reduced)
+			if rr.isLegitimate(value) {
+				residues[i] = mod(value, system.moduli[i])
+				rr.corrected = rr.corrected + 1
+				break
+			}
+		}
+	}
+}
+
+// predict returns the predicted class index with the sums of every layer checked after an optional corruption
+func (rr *RedundantRNSClassifier) predict(rc *RNSClassifier, inputArr []float64, corrupt func(int, [][]int64)) (int, error) {
+	_, activations, err := rc.forwardLayers(inputArr, func(layer int, sums [][]int64) {
+		if corrupt != nil {
+			corrupt(layer, sums)
+		}
+		rr.check(layer, sums)
+	})
+	if err != nil {
+		return 0, err
+	}
+	raw := activations[len(activations)-1]
+	outputs := make([]float64, len(raw))
+	for i, value := range raw {
+		outputs[i] = rc.config.activations.ToFloat(value)
+	}
+	return classifyOutputs(outputs), nil
+}
+
+// Predict return the predicted class index of the checked network in the same way as Classifier.Predict
+func (rr *RedundantRNSClassifier) Predict(inputArr []float64) (int, error) {
+	return rr.predict(rr.rns, inputArr, nil)
+}
+
+// Score return the accuracy of the checked network in the same way as Classifier.Score
+func (rr *RedundantRNSClassifier) Score(data [][]float64, target [][]float64) (float64, error) {
+	return scoreClassifier(rr.Predict, data, target)
+}
+
+// InjectFaults corrupts the residues of the weights or biases and returns the number of flips
+func (rr *RedundantRNSClassifier) InjectFaults(injector *FaultInjector, target FaultTarget) (int, error) {
+	corrupted, flips, err := rr.rns.InjectFaults(injector, target)
+	if err != nil {
+		return 0, err
+	}
+	rr.rns = corrupted
+	return flips, nil
+}
+
+// faultLayers returns the number of layers exposed to faults
+func (rr *RedundantRNSClassifier) faultLayers() int {
+	return len(rr.rns.layers)
+}
+
+// faulty returns a predictor of the checked network exposed to faults in a target counting the flips
+func (rr *RedundantRNSClassifier) faulty(injector *FaultInjector, target FaultTarget, flips *int) (func([]float64) (int, error), error) {
+	if target != FaultActivations {
+		corrupted, n, err := rr.rns.InjectFaults(injector, target)
+		*flips = *flips + n
+		if err != nil {
+			return nil, err
+		}
+		return func(inputArr []float64) (int, error) {
+			return rr.predict(corrupted, inputArr, nil)
+		}, nil
+	}
+
+	return func(inputArr []float64) (int, error) {
+		return rr.predict(rr.rns, inputArr, func(layer int, sums [][]int64) {
+			if !injector.inLayer(layer) {
+				return
+			}
+			for _, residues := range sums {
+				*flips = *flips + injector.flipResidues(residues, rr.rns.system)
+			}
+		})
+	}, nil
+}
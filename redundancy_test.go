@@ -0,0 +1,118 @@
+package gomlp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTMRCorrectsSingleCopyFaults(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 8, 3}, 10)
+	tmr := NewTMRClassifier(mlp)
+	if tmr.Detected() != 0 {
+		t.Fatalf("got %d detected words before any fault", tmr.Detected())
+	}
+
+	// faults in a single copy are always outvoted by the other two
+	injector, _ := NewFaultInjector(0.05, rand.NewSource(5))
+	copies := tmr.Copies()
+	corrupted, flips, err := copies[1].InjectFaults(injector, FaultWeights)
+	if err != nil || flips == 0 {
+		t.Fatalf("got %d flips with error %v", flips, err)
+	}
+	tmr.copies[1] = corrupted
+	tmr.voted = tmr.vote()
+	detected, corrected := tmr.Detected(), tmr.Corrected()
+	if detected == 0 || corrected != detected {
+		t.Errorf("got %d detected and %d corrected words", detected, corrected)
+	}
+	for _, row := range data {
+		want, _ := mlp.Predict(row)
+		got, err := tmr.Predict(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("got class %d, want %d", got, want)
+		}
+	}
+	if tmr.Detected() != detected || tmr.Corrected() != corrected {
+		t.Errorf("predictions changed the counters from %d and %d to %d and %d", detected, corrected, tmr.Detected(), tmr.Corrected())
+	}
+
+	tmr.ResetCounters()
+	if _, err = tmr.InjectFaults(injector, FaultBiases); err != nil {
+		t.Fatal(err)
+	}
+	if tmr.Detected() == 0 {
+		t.Errorf("got no detected words after injecting faults into every copy")
+	}
+}
+
+// testRRNSConfig returns a ten bit datapath whose sums stay small enough for redundant residue checks
+func testRRNSConfig(t *testing.T) *FixedPointConfig {
+	t.Helper()
+	weights, _ := NewQFormat(4, 6)
+	activations, _ := NewQFormat(4, 6)
+	accumulator, _ := NewQFormat(16, 12)
+	config, err := NewFixedPointConfig(weights, activations, accumulator, Saturate, RoundHalfEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestRedundantRNSCorrectsAWrongResidue(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 12, 8, 3}, 10)
+	config := testRRNSConfig(t)
+	system, err := NewRNS(1021, 1023, 1024, 1031, 1033)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr, err := NewRedundantRNSClassifier(mlp, system, 2, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clean, err := NewRNSClassifier(mlp, system, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := rand.New(rand.NewSource(6))
+	for _, row := range data[:50] {
+		want, _ := clean.Predict(row)
+		// every sum of every layer has one residue replaced by a wrong one
+		got, err := rr.predict(rr.rns, row, func(layer int, sums [][]int64) {
+			for _, residues := range sums {
+				i := random.Intn(len(residues))
+				residues[i] = (residues[i] + 1 + random.Int63n(system.moduli[i]-1)) % system.moduli[i]
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("got class %d, want %d", got, want)
+		}
+	}
+	if rr.Detected() == 0 || rr.Corrected() != rr.Detected() {
+		t.Errorf("got %d detected and %d corrected sums", rr.Detected(), rr.Corrected())
+	}
+}
+
+func TestRedundantRNSRejectsSmallRanges(t *testing.T) {
+	mlp, _, _ := testClassifier(t, []int{2, 12, 8, 3}, 1)
+	config := testRRNSConfig(t)
+	cases := [][]int64{
+		{31, 32, 33, 35, 37},
+		{1021, 1023, 1024, 1031, 511},
+	}
+	for _, moduli := range cases {
+		system, err := NewRNS(moduli...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = NewRedundantRNSClassifier(mlp, system, 2, config); err != ErrRNSModuli {
+			t.Errorf("%v: got error %v, want %v", moduli, err, ErrRNSModuli)
+		}
+	}
+}
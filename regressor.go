@@ -0,0 +1,145 @@
+package gomlp
+
+import (
+	"io"
+	"math"
+)
+
+// NewRegressor return a new pointer to the Regressor Class with a layer for every size given
+// The output layer uses the identity activation and the mean squared error loss
+func NewRegressor(layerSizes []int, options ...Option) (*Regressor, error) {
+	nn, err := newNetwork(layerSizes, options...)
+	if err != nil {
+		return &Regressor{}, err
+	}
+	nn.layers[len(nn.layers)-1].activationFunc = identity
+
+	return &Regressor{
+		nn,
+	}, nil
+}
+
+// NewRegressorFromDir return a new pointer to the Regressor Class from a directory written by SaveToDir
+func NewRegressorFromDir(path string) (*Regressor, error) {
+	doc, err := readDocumentFromDir(path)
+	if err != nil {
+		return &Regressor{}, err
+	}
+	return newRegressorFromDocument(doc)
+}
+
+// LoadRegressor return a new pointer to the Regressor Class from a model file written by Save
+func LoadRegressor(r io.Reader) (*Regressor, error) {
+	doc, err := readDocument(r)
+	if err != nil {
+		return &Regressor{}, err
+	}
+	return newRegressorFromDocument(doc)
+}
+
+// newRegressorFromDocument return a new pointer to the Regressor Class described by a model file
+func newRegressorFromDocument(doc *savedModel) (*Regressor, error) {
+	if doc.Kind != "" && doc.Kind != RegressorModel {
+		return &Regressor{}, ErrModelKind
+	}
+
+	nn, err := newNetworkFromDocument(doc)
+	if err != nil {
+		return &Regressor{}, err
+	}
+
+	return &Regressor{
+		nn,
+	}, nil
+}
+
+// Predict return a slice of the predicted output values of a trained neural network
+func (r *Regressor) Predict(inputArr []float64) ([]float64, error) {
+	output, err := r.predict(inputArr)
+	if err != nil {
+		return []float64{}, err
+	}
+	return output.ConvertFromMatrixToArray1D(), nil
+}
+
+// Train is used to train a neural network on continuous targets with a column for every output node
+func (r *Regressor) Train(data, targetArr [][]float64, epochs int) error {
+	for _, row := range targetArr {
+		if len(row) != r.outputNodes() {
+			return ErrTargetDimension
+		}
+	}
+
+	return r.train(data, targetArr, epochs, r.SaveToDir)
+}
+
+// SaveToDir writes the model file into a directory creating it if needed
+func (r *Regressor) SaveToDir(path string) error {
+	return writeDocumentToDir(path, r.document(RegressorModel))
+}
+
+// Save writes the model as a single versioned model file
+func (r *Regressor) Save(w io.Writer) error {
+	return writeDocument(w, r.document(RegressorModel))
+}
+
+// Score return the coefficient of determination of the predictions averaged over the outputs
+func (r *Regressor) Score(data [][]float64, target [][]float64) (float64, error) {
+	score, err := r.Evaluate(data, target)
+	if err != nil {
+		return 0, err
+	}
+	return score.R2, nil
+}
+
+// Evaluate return the coefficient of determination, root mean squared error and mean absolute error of the predictions
+func (r *Regressor) Evaluate(data [][]float64, target [][]float64) (RegressionScore, error) {
+	var score RegressionScore
+	if len(data) != len(target) || len(data) == 0 {
+		return score, ErrRowColumnDimension
+	}
+
+	outputNodes := r.outputNodes()
+	mean := make([]float64, outputNodes)
+	for _, row := range target {
+		if len(row) != outputNodes {
+			return score, ErrTargetDimension
+		}
+		for j, element := range row {
+			mean[j] = mean[j] + element/float64(len(target))
+		}
+	}
+
+	residual := make([]float64, outputNodes)
+	total := make([]float64, outputNodes)
+	var squared, absolute float64
+	for i, row := range data {
+		prediction, err := r.Predict(row)
+		if err != nil {
+			return score, err
+		}
+		for j := range prediction {
+			diff := target[i][j] - prediction[j]
+			residual[j] = residual[j] + diff*diff
+			total[j] = total[j] + math.Pow(target[i][j]-mean[j], 2)
+			squared = squared + diff*diff
+			absolute = absolute + math.Abs(diff)
+		}
+	}
+
+	for j := range residual {
+		if total[j] == 0 {
+			if residual[j] == 0 {
+				score.R2 = score.R2 + 1
+			}
+			continue
+		}
+		score.R2 = score.R2 + 1 - residual[j]/total[j]
+	}
+	score.R2 = score.R2 / float64(outputNodes)
+
+	count := float64(len(data) * outputNodes)
+	score.RMSE = math.Sqrt(squared / count)
+	score.MAE = absolute / count
+	return score, nil
+}
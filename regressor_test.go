@@ -0,0 +1,59 @@
+package gomlp
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testRegressionData returns rows of two features with a smooth target of two outputs
+func testRegressionData(rows int) ([][]float64, [][]float64) {
+	random := rand.New(rand.NewSource(12))
+	data := make([][]float64, rows)
+	target := make([][]float64, rows)
+	for i := range data {
+		x, y := random.Float64()*2-1, random.Float64()*2-1
+		data[i] = []float64{x, y}
+		target[i] = []float64{math.Sin(2*x) + y, x * y}
+	}
+	return data, target
+}
+
+func TestRegressorFitsSmoothTargets(t *testing.T) {
+	data, target := testRegressionData(400)
+	r, err := NewRegressor([]int{2, 16, 16, 2}, WithSeed(2), WithOptimizer(NewAdam(0.01, 0.9, 0.999, 1e-8)), WithBatchSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.SetActivations([]string{Tanh, Tanh, Identity}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Train(data, target, 150); err != nil {
+		t.Fatal(err)
+	}
+	score, err := r.Evaluate(data, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score.R2 < 0.9 || score.RMSE > 0.3 || score.MAE > score.RMSE {
+		t.Errorf("got score %+v", score)
+	}
+
+	var buffer bytes.Buffer
+	if err = r.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRegressor(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameNetworks(t, loaded.network, r.network)
+
+	if err = r.Train(data, [][]float64{{1}}, 1); err != ErrTargetDimension {
+		t.Errorf("got error %v, want %v", err, ErrTargetDimension)
+	}
+	if _, err = r.Evaluate(data, target[:1]); err != ErrRowColumnDimension {
+		t.Errorf("got error %v, want %v", err, ErrRowColumnDimension)
+	}
+}
@@ -0,0 +1,458 @@
+package gomlp
+
+import "math/bits"
+
+var (
+	maxRNSModulus = int64(1) << 31
+	maxRNSRange   = int64(1) << 62
+)
+
+// NewRNS return a new pointer to a residue number system over pairwise coprime moduli
+// Each modulus is smaller than 2^31 and their product smaller than 2^62
+func NewRNS(moduli ...int64) (*RNS, error) {
+	if len(moduli) < 2 {
+		return &RNS{}, ErrRNSModuli
+	}
+
+	dynamicRange := int64(1)
+	for i, m := range moduli {
+		if m < 2 || m >= maxRNSModulus {
+			return &RNS{}, ErrRNSModuli
+		}
+		for _, other := range moduli[:i] {
+			if gcd(m, other) != 1 {
+				return &RNS{}, ErrRNSModuli
+			}
+		}
+		if dynamicRange > (maxRNSRange-1)/m {
+			return &RNS{}, ErrRNSModuli
+		}
+		dynamicRange = dynamicRange * m
+	}
+
+	inverses := make([][]int64, len(moduli))
+	crt := make([]int64, len(moduli))
+	for i, m := range moduli {
+		inverses[i] = make([]int64, len(moduli))
+		for j, other := range moduli {
+			if i != j {
+				inverses[i][j] = modInverse(m%other, other)
+			}
+		}
+		crt[i] = modInverse((dynamicRange/m)%m, m)
+	}
+
+	return &RNS{
+		append([]int64(nil), moduli...),
+		dynamicRange,
+		inverses,
+		crt,
+	}, nil
+}
+
+// NewSpecialRNS return a new pointer to the residue number system over the moduli set {2^n-1, 2^n, 2^n+1}
+func NewSpecialRNS(n int) (*RNS, error) {
+	if n < 2 || n > 20 {
+		return &RNS{}, ErrRNSModuli
+	}
+	power := int64(1) << uint(n)
+	return NewRNS(power-1, power, power+1)
+}
+
+// gcd returns the greatest common divisor of two positive numbers
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// modInverse returns the multiplicative inverse of a number modulo a coprime modulus
+func modInverse(a, m int64) int64 {
+	t, newT := int64(0), int64(1)
+	r, newR := m, a
+	for newR != 0 {
+		quotient := r / newR
+		t, newT = newT, t-quotient*newT
+		r, newR = newR, r-quotient*newR
+	}
+	if t < 0 {
+		t = t + m
+	}
+	return t
+}
+
+// mod returns the non negative remainder of a number modulo a modulus
+func mod(a, m int64) int64 {
+	r := a % m
+	if r < 0 {
+		r = r + m
+	}
+	return r
+}
+
+// mulMod returns the product of two non negative numbers modulo a modulus without overflowing
+func mulMod(a, b, m int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	return int64(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// Moduli returns the moduli of the system
+func (s *RNS) Moduli() []int64 {
+	return append([]int64(nil), s.moduli...)
+}
+
+// DynamicRange returns the product of the moduli
+// Signed numbers from -DynamicRange/2 up to (DynamicRange-1)/2 are represented uniquely
+func (s *RNS) DynamicRange() int64 {
+	return s.dynamicRange
+}
+
+// checkResidues returns an error when residues do not belong to the system
+func (s *RNS) checkResidues(residues ...[]int64) error {
+	for _, r := range residues {
+		if len(r) != len(s.moduli) {
+			return ErrRNSResidues
+		}
+	}
+	return nil
+}
+
+// Forward converts a signed integer into its residues
+func (s *RNS) Forward(x int64) []int64 {
+	residues := make([]int64, len(s.moduli))
+	for i, m := range s.moduli {
+		residues[i] = mod(x, m)
+	}
+	return residues
+}
+
+// Add returns the residues of the sum of two numbers
+func (s *RNS) Add(a, b []int64) ([]int64, error) {
+	if err := s.checkResidues(a, b); err != nil {
+		return []int64{}, err
+	}
+	residues := make([]int64, len(s.moduli))
+	for i, m := range s.moduli {
+		residues[i] = (a[i] + b[i]) % m
+	}
+	return residues, nil
+}
+
+// Subtract returns the residues of the difference of two numbers
+func (s *RNS) Subtract(a, b []int64) ([]int64, error) {
+	if err := s.checkResidues(a, b); err != nil {
+		return []int64{}, err
+	}
+	residues := make([]int64, len(s.moduli))
+	for i, m := range s.moduli {
+		residues[i] = mod(a[i]-b[i], m)
+	}
+	return residues, nil
+}
+
+// Multiply returns the residues of the product of two numbers
+func (s *RNS) Multiply(a, b []int64) ([]int64, error) {
+	if err := s.checkResidues(a, b); err != nil {
+		return []int64{}, err
+	}
+	residues := make([]int64, len(s.moduli))
+	for i, m := range s.moduli {
+		residues[i] = (a[i] * b[i]) % m
+	}
+	return residues, nil
+}
+
+// CRT converts residues back into an unsigned integer below the dynamic range using the Chinese Remainder Theorem
+func (s *RNS) CRT(residues []int64) (int64, error) {
+	if err := s.checkResidues(residues); err != nil {
+		return 0, err
+	}
+	var x int64
+	for i, m := range s.moduli {
+		digit := (residues[i] * s.crt[i]) % m
+		x = (x + mulMod(s.dynamicRange/m, digit, s.dynamicRange)) % s.dynamicRange
+	}
+	return x, nil
+}
+
+// MixedRadixDigits returns the mixed radix digits of residues with the first digit least significant
+// The number equals d0 + d1*m0 + d2*m0*m1 + ... for the moduli m0, m1, ...
+func (s *RNS) MixedRadixDigits(residues []int64) ([]int64, error) {
+	if err := s.checkResidues(residues); err != nil {
+		return []int64{}, err
+	}
+	digits := append([]int64(nil), residues...)
+	for i := range s.moduli {
+		for j := i + 1; j < len(s.moduli); j++ {
+			digits[j] = (mod(digits[j]-digits[i], s.moduli[j]) * s.inverses[i][j]) % s.moduli[j]
+		}
+	}
+	return digits, nil
+}
+
+// MixedRadix converts residues back into an unsigned integer below the dynamic range using mixed radix conversion
+func (s *RNS) MixedRadix(residues []int64) (int64, error) {
+	digits, err := s.MixedRadixDigits(residues)
+	if err != nil {
+		return 0, err
+	}
+	var x int64
+	for i := len(digits) - 1; i >= 0; i-- {
+		x = x*s.moduli[i] + digits[i]
+	}
+	return x, nil
+}
+
+// signed maps an unsigned integer below the dynamic range to the signed range
+func (s *RNS) signed(x int64) int64 {
+	if x > (s.dynamicRange-1)/2 {
+		return x - s.dynamicRange
+	}
+	return x
+}
+
+// Reverse converts residues back into a signed integer using the Chinese Remainder Theorem
+func (s *RNS) Reverse(residues []int64) (int64, error) {
+	x, err := s.CRT(residues)
+	if err != nil {
+		return 0, err
+	}
+	return s.signed(x), nil
+}
+
+// ReverseMixedRadix converts residues back into a signed integer using mixed radix conversion
+func (s *RNS) ReverseMixedRadix(residues []int64) (int64, error) {
+	x, err := s.MixedRadix(residues)
+	if err != nil {
+		return 0, err
+	}
+	return s.signed(x), nil
+}
+
+// Compare returns -1, 0 or 1 when the signed number of a is smaller than, equal to or larger than that of b
+// The numbers are offset into the unsigned range and their mixed radix digits compared from the most significant
+func (s *RNS) Compare(a, b []int64) (int, error) {
+	offset := s.Forward(s.dynamicRange / 2)
+	shiftedA, err := s.Add(a, offset)
+	if err != nil {
+		return 0, err
+	}
+	shiftedB, err := s.Add(b, offset)
+	if err != nil {
+		return 0, err
+	}
+	digitsA, _ := s.MixedRadixDigits(shiftedA)
+	digitsB, _ := s.MixedRadixDigits(shiftedB)
+	for i := len(digitsA) - 1; i >= 0; i-- {
+		if digitsA[i] < digitsB[i] {
+			return -1, nil
+		}
+		if digitsA[i] > digitsB[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// Scale returns the residues of a signed number divided by one of the moduli and rounded toward negative infinity
+// The other residues are scaled in place and the residue of the divisor is recovered by base extension
+func (s *RNS) Scale(residues []int64, modulus int) ([]int64, error) {
+	if err := s.checkResidues(residues); err != nil {
+		return []int64{}, err
+	}
+	if modulus < 0 || modulus >= len(s.moduli) {
+		return []int64{}, ErrRNSModuli
+	}
+
+	offset := s.Forward(s.dynamicRange / 2)
+	shifted, _ := s.Add(residues, offset)
+	var others []int
+	for j := range s.moduli {
+		if j != modulus {
+			others = append(others, j)
+		}
+	}
+
+	// digits of the shifted quotient in the mixed radix of the other moduli
+	digits := make([]int64, len(others))
+	for k, j := range others {
+		digits[k] = (mod(shifted[j]-shifted[modulus], s.moduli[j]) * s.inverses[modulus][j]) % s.moduli[j]
+	}
+	for k, i := range others {
+		for l := k + 1; l < len(others); l++ {
+			j := others[l]
+			digits[l] = (mod(digits[l]-digits[k], s.moduli[j]) * s.inverses[i][j]) % s.moduli[j]
+		}
+	}
+	var quotient int64
+	for k := len(others) - 1; k >= 0; k-- {
+		quotient = quotient*s.moduli[others[k]] + digits[k]
+	}
+
+	divisor := s.moduli[modulus]
+	half := s.dynamicRange / 2
+	quotient = quotient - half/divisor
+	if shifted[modulus] < half%divisor {
+		quotient = quotient - 1
+	}
+	return s.Forward(quotient), nil
+}
+
+// newRNSNetwork return a new pointer to the network quantized to the formats of the config and held in residues
+// Products keep the fraction bits of the weights and the activations and accumulate modulo the dynamic range
+// so the accumulator format of the config is replaced by the largest format a range of the sums covers
+func newRNSNetwork(nn *network, system *RNS, config *FixedPointConfig, sumRange int64) (*rnsNetwork, error) {
+	if system == nil || len(system.moduli) == 0 || config == nil {
+		return &rnsNetwork{}, ErrRNSModuli
+	}
+
+	fractionBits := config.weights.fractionBits + config.activations.fractionBits
+	accumulator, err := NewQFormat(bits.Len64(uint64(sumRange))-1-fractionBits, fractionBits)
+	if err != nil {
+		return &rnsNetwork{}, err
+	}
+
+	layers := make([]rnsLayer, len(nn.layers))
+	for i, layer := range nn.layers {
+		weights := make([][][]int64, layer.outputNodes)
+		bias := make([][]int64, layer.outputNodes)
+		for r := 0; r < layer.outputNodes; r++ {
+			weights[r] = make([][]int64, layer.inputNodes)
+			for c := 0; c < layer.inputNodes; c++ {
+				weights[r][c] = system.Forward(config.weights.Quantize(layer.weights.data[r][c], config.rounding, config.overflow))
+			}
+			bias[r] = system.Forward(accumulator.Quantize(layer.bias.data[r][0], config.rounding, Saturate))
+		}
+		layers[i] = rnsLayer{weights, bias, layer.activationFunc}
+	}
+
+	return &rnsNetwork{
+		system,
+		config,
+		accumulator,
+		layers,
+	}, nil
+}
+
+// NewRNSClassifier return a new pointer to a trained Classifier evaluated in a residue number system
+func NewRNSClassifier(mlp *Classifier, system *RNS, config *FixedPointConfig) (*RNSClassifier, error) {
+	if system == nil {
+		return &RNSClassifier{}, ErrRNSModuli
+	}
+	rn, err := newRNSNetwork(mlp.network, system, config, system.dynamicRange)
+	if err != nil {
+		return &RNSClassifier{}, err
+	}
+
+	classes := append([]float64(nil), mlp.Classes...)
+
+	return &RNSClassifier{
+		rn,
+		classes,
+	}, nil
+}
+
+// System returns the residue number system of the network
+func (rn *rnsNetwork) System() *RNS {
+	return rn.system
+}
+
+// Accumulator returns the format of the reverse converted multiply accumulate results
+func (rn *rnsNetwork) Accumulator() QFormat {
+	return rn.accumulator
+}
+
+// ForwardLayers returns the residues of the multiply accumulate results and the activation format outputs of every layer
+// Every layer accumulates in residues, reverse converts the sums with the Chinese Remainder Theorem, applies the
+// activation function and forward converts the quantized outputs for the next layer
+// Only the residue arithmetic and the reverse conversion are integer arithmetic that a datapath repeats bit for bit
+// The activation functions are a float64 reference evaluated on the reverse converted sums and quantized to the
+// activation format so a datapath with its own activation tables matches the residues but may differ in the outputs
+// The outputs start with the quantized inputs and so hold one slice more than the accumulator residues
+func (rn *rnsNetwork) ForwardLayers(inputArr []float64) ([][][]int64, [][]int64, error) {
+	return rn.forwardLayers(inputArr, nil)
+}
+
+// forwardLayers returns the residues of the multiply accumulate results and the outputs of every layer
+// A non nil corrupt function may change the residues of the sums of a layer before they are reverse converted
+func (rn *rnsNetwork) forwardLayers(inputArr []float64, corrupt func(layer int, sums [][]int64)) ([][][]int64, [][]int64, error) {
+	config := rn.config
+	inputs := make([]int64, len(inputArr))
+	for i, x := range inputArr {
+		inputs[i] = config.activations.Quantize(x, config.rounding, config.overflow)
+	}
+
+	activations := [][]int64{inputs}
+	var accumulators [][][]int64
+	for i, layer := range rn.layers {
+		values := activations[len(activations)-1]
+		if len(layer.weights) == 0 || len(values) != len(layer.weights[0]) {
+			return accumulators, activations, ErrRowColumnDimension
+		}
+		residues := make([][]int64, len(values))
+		for c, value := range values {
+			residues[c] = rn.system.Forward(value)
+		}
+
+		sums := make([][]int64, len(layer.weights))
+		reals, _ := NewMatrix(len(layer.weights), 1)
+		for r, row := range layer.weights {
+			sum := layer.bias[r]
+			for c, weight := range row {
+				product, _ := rn.system.Multiply(weight, residues[c])
+				sum, _ = rn.system.Add(sum, product)
+			}
+			sums[r] = sum
+		}
+		if corrupt != nil {
+			corrupt(i, sums)
+		}
+		for r, sum := range sums {
+			value, _ := rn.system.Reverse(sum)
+			reals.data[r][0] = rn.accumulator.ToFloat(value)
+		}
+
+		outputs := make([]int64, len(sums))
+		for r, value := range layer.activationFunc.apply(reals).ConvertFromMatrixToArray1D() {
+			outputs[r] = config.activations.Quantize(value, config.rounding, config.overflow)
+		}
+		accumulators = append(accumulators, sums)
+		activations = append(activations, outputs)
+	}
+	return accumulators, activations, nil
+}
+
+// Outputs returns the outputs of the network for a single input row converted back to real numbers
+func (rn *rnsNetwork) Outputs(inputArr []float64) ([]float64, error) {
+	_, activations, err := rn.forwardLayers(inputArr, nil)
+	if err != nil {
+		return []float64{}, err
+	}
+	raw := activations[len(activations)-1]
+	outputs := make([]float64, len(raw))
+	for i, value := range raw {
+		outputs[i] = rn.config.activations.ToFloat(value)
+	}
+	return outputs, nil
+}
+
+// Predict return the predicted class index of the residue number system network in the same way as Classifier.Predict
+// The activation functions are a float64 reference as described for ForwardLayers
+func (rc *RNSClassifier) Predict(inputArr []float64) (int, error) {
+	outputs, err := rc.Outputs(inputArr)
+	if err != nil {
+		return 0, err
+	}
+	return classifyOutputs(outputs), nil
+}
+
+// Score return the accuracy of the residue number system network in the same way as Classifier.Score
+func (rc *RNSClassifier) Score(data [][]float64, target [][]float64) (float64, error) {
+	return scoreClassifier(rc.Predict, data, target)
+}
+
+// Agreement return the share of rows where the residue number system network predicts the same class as a Classifier
+func (rc *RNSClassifier) Agreement(mlp *Classifier, data [][]float64) (float64, error) {
+	return agreement(rc.Predict, mlp.Predict, data)
+}
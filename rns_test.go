@@ -0,0 +1,143 @@
+package gomlp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// floorDiv returns a divided by b rounded toward negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q = q - 1
+	}
+	return q
+}
+
+func TestRNSConversionsOverTheDynamicRange(t *testing.T) {
+	system, err := NewSpecialRNS(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if system.DynamicRange() != 504 {
+		t.Fatalf("got dynamic range %d, want 504", system.DynamicRange())
+	}
+	for x := int64(-252); x <= 251; x++ {
+		residues := system.Forward(x)
+		if got, err := system.Reverse(residues); err != nil || got != x {
+			t.Errorf("Reverse(%d): got %d with error %v", x, got, err)
+		}
+		if got, err := system.ReverseMixedRadix(residues); err != nil || got != x {
+			t.Errorf("ReverseMixedRadix(%d): got %d with error %v", x, got, err)
+		}
+		if got, _ := system.CRT(residues); got != mod(x, 504) {
+			t.Errorf("CRT(%d): got %d, want %d", x, got, mod(x, 504))
+		}
+		if got, _ := system.MixedRadix(residues); got != mod(x, 504) {
+			t.Errorf("MixedRadix(%d): got %d, want %d", x, got, mod(x, 504))
+		}
+		for i, m := range system.Moduli() {
+			scaled, err := system.Scale(residues, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := system.Reverse(scaled); got != floorDiv(x, m) {
+				t.Errorf("Scale(%d, %d): got %d, want %d", x, m, got, floorDiv(x, m))
+			}
+		}
+	}
+}
+
+func TestRNSCompareAndArithmetic(t *testing.T) {
+	system, _ := NewRNS(5, 7, 9, 4)
+	half := system.DynamicRange() / 2
+	for a := -half; a < half; a = a + 7 {
+		for b := -half; b < half; b = b + 5 {
+			ra, rb := system.Forward(a), system.Forward(b)
+			want := 0
+			if a < b {
+				want = -1
+			} else if a > b {
+				want = 1
+			}
+			if got, _ := system.Compare(ra, rb); got != want {
+				t.Fatalf("Compare(%d, %d): got %d, want %d", a, b, got, want)
+			}
+
+			sum, _ := system.Add(ra, rb)
+			difference, _ := system.Subtract(ra, rb)
+			product, _ := system.Multiply(ra, rb)
+			for _, tc := range []struct {
+				residues []int64
+				want     int64
+			}{{sum, a + b}, {difference, a - b}, {product, a * b}} {
+				if tc.want < -half || tc.want >= half {
+					continue
+				}
+				if got, _ := system.Reverse(tc.residues); got != tc.want {
+					t.Fatalf("%d and %d: got %d, want %d", a, b, got, tc.want)
+				}
+			}
+		}
+	}
+	if _, err := system.Add([]int64{1}, []int64{1, 2, 3, 4}); err != ErrRNSResidues {
+		t.Errorf("got error %v, want %v", err, ErrRNSResidues)
+	}
+	if _, err := system.Scale(system.Forward(1), 4); err != ErrRNSModuli {
+		t.Errorf("got error %v, want %v", err, ErrRNSModuli)
+	}
+}
+
+func TestRNSWideModuli(t *testing.T) {
+	system, err := NewSpecialRNS(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	half := system.DynamicRange() / 2
+	values := []int64{-half, -half + 1, -1, 0, 1, half - 1}
+	random := rand.New(rand.NewSource(4))
+	for i := 0; i < 1000; i++ {
+		values = append(values, random.Int63n(system.DynamicRange())-half)
+	}
+	for _, x := range values {
+		residues := system.Forward(x)
+		crt, _ := system.Reverse(residues)
+		mixed, _ := system.ReverseMixedRadix(residues)
+		if crt != x || mixed != x {
+			t.Fatalf("%d: got %d by CRT and %d by mixed radix", x, crt, mixed)
+		}
+	}
+}
+
+func TestNewRNSRejectsInvalidModuli(t *testing.T) {
+	for _, moduli := range [][]int64{{7}, {6, 9}, {1, 7}, {7, 1 << 31}, {1<<31 - 1, 1<<31 - 3, 1<<31 - 5}} {
+		if _, err := NewRNS(moduli...); err != ErrRNSModuli {
+			t.Errorf("%v: got error %v, want %v", moduli, err, ErrRNSModuli)
+		}
+	}
+	for _, n := range []int{1, 21} {
+		if _, err := NewSpecialRNS(n); err != ErrRNSModuli {
+			t.Errorf("%d: got error %v, want %v", n, err, ErrRNSModuli)
+		}
+	}
+}
+
+func TestRNSClassifierMatchesFixedPoint(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 12, 8, 3}, 30)
+	config := testFixedPointConfig(t)
+	system, err := NewSpecialRNS(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := NewRNSClassifier(mlp, system, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agreement, err := rc.Agreement(mlp, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agreement < 0.97 {
+		t.Errorf("got agreement %v, want at least 0.97", agreement)
+	}
+}
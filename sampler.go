@@ -0,0 +1,172 @@
+package gomlp
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Sampler returns the order in which the rows of a dataset are visited during an epoch
+type Sampler interface {
+	// Indices returns the row indices to visit in the next epoch of a dataset with the given number of rows
+	// All the randomness is drawn from the given generator so that epochs can be reproduced
+	Indices(rows int, random *rand.Rand) ([]int, error)
+}
+
+// ShuffleSampler visits every row once per epoch in a new random order
+type ShuffleSampler struct{}
+
+// SequentialSampler visits every row once per epoch in the order of the dataset
+type SequentialSampler struct{}
+
+// StratifiedSampler visits every row once per epoch with the classes spread evenly over the epoch
+type StratifiedSampler struct {
+	classes map[float64][]int
+	labels  []float64
+}
+
+// WeightedSampler draws as many rows as the dataset has with replacement in proportion to their weights
+type WeightedSampler struct {
+	cumulative []float64
+}
+
+// NewShuffleSampler return a new pointer to a ShuffleSampler
+func NewShuffleSampler() *ShuffleSampler {
+	return &ShuffleSampler{}
+}
+
+// NewSequentialSampler return a new pointer to a SequentialSampler
+func NewSequentialSampler() *SequentialSampler {
+	return &SequentialSampler{}
+}
+
+// NewStratifiedSampler return a new pointer to a StratifiedSampler for the classes in the first column of the targets
+func NewStratifiedSampler(targets [][]float64) (*StratifiedSampler, error) {
+	classes := make(map[float64][]int)
+	var labels []float64
+	for index, row := range targets {
+		if len(row) == 0 {
+			return &StratifiedSampler{}, ErrTargetDimension
+		}
+		if _, ok := classes[row[0]]; !ok {
+			labels = append(labels, row[0])
+		}
+		classes[row[0]] = append(classes[row[0]], index)
+	}
+	sort.Float64s(labels)
+
+	return &StratifiedSampler{
+		classes,
+		labels,
+	}, nil
+}
+
+// NewWeightedSampler return a new pointer to a WeightedSampler with a non negative weight for every row
+func NewWeightedSampler(weights []float64) (*WeightedSampler, error) {
+	cumulative := make([]float64, len(weights))
+	var total float64
+	for i, weight := range weights {
+		if weight < 0 {
+			return &WeightedSampler{}, ErrSamplerWeights
+		}
+		total = total + weight
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return &WeightedSampler{}, ErrSamplerWeights
+	}
+
+	return &WeightedSampler{
+		cumulative,
+	}, nil
+}
+
+// Indices returns a random permutation of the rows
+func (s *ShuffleSampler) Indices(rows int, random *rand.Rand) ([]int, error) {
+	return random.Perm(rows), nil
+}
+
+// Indices returns the rows in the order of the dataset
+func (s *SequentialSampler) Indices(rows int, random *rand.Rand) ([]int, error) {
+	indices := make([]int, rows)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices, nil
+}
+
+// Indices returns a random permutation of the rows of every class interleaved in proportion to the class sizes
+func (s *StratifiedSampler) Indices(rows int, random *rand.Rand) ([]int, error) {
+	var total int
+	for _, members := range s.classes {
+		total = total + len(members)
+	}
+	if total != rows {
+		return []int{}, ErrSamplerRows
+	}
+
+	indices := make([]int, 0, rows)
+	positions := make([]float64, 0, rows)
+	for _, label := range s.labels {
+		members := s.classes[label]
+		for k, j := range random.Perm(len(members)) {
+			indices = append(indices, members[j])
+			positions = append(positions, (float64(k)+random.Float64())/float64(len(members)))
+		}
+	}
+
+	sort.Sort(byPosition{indices, positions})
+	return indices, nil
+}
+
+// Indices returns as many rows as the dataset has drawn with replacement in proportion to their weights
+func (s *WeightedSampler) Indices(rows int, random *rand.Rand) ([]int, error) {
+	if rows != len(s.cumulative) {
+		return []int{}, ErrSamplerRows
+	}
+
+	total := s.cumulative[len(s.cumulative)-1]
+	indices := make([]int, rows)
+	for i := range indices {
+		draw := random.Float64() * total
+		indices[i] = sort.Search(len(s.cumulative), func(j int) bool {
+			return s.cumulative[j] > draw
+		})
+	}
+	return indices, nil
+}
+
+// byPosition sorts indices by their relative position within an epoch
+type byPosition struct {
+	indices   []int
+	positions []float64
+}
+
+func (b byPosition) Len() int {
+	return len(b.indices)
+}
+
+func (b byPosition) Less(i, j int) bool {
+	return b.positions[i] < b.positions[j]
+}
+
+func (b byPosition) Swap(i, j int) {
+	b.indices[i], b.indices[j] = b.indices[j], b.indices[i]
+	b.positions[i], b.positions[j] = b.positions[j], b.positions[i]
+}
+
+// Batches splits the indices of an epoch into consecutive batches of at most the given size
+func Batches(indices []int, batchSize int) ([][]int, error) {
+	if batchSize < 1 {
+		return [][]int{}, ErrBatchSize
+	}
+
+	var batches [][]int
+	for start := 0; start < len(indices); start = start + batchSize {
+		end := start + batchSize
+		if end > len(indices) {
+			end = len(indices)
+		}
+		batches = append(batches, indices[start:end])
+	}
+	return batches, nil
+}
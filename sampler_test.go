@@ -0,0 +1,113 @@
+package gomlp
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSamplersVisitEveryRowOnce(t *testing.T) {
+	targets := make([][]float64, 31)
+	for i := range targets {
+		targets[i] = []float64{float64(i % 3)}
+	}
+	stratified, err := NewStratifiedSampler(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samplers := map[string]Sampler{
+		"shuffle":    NewShuffleSampler(),
+		"sequential": NewSequentialSampler(),
+		"stratified": stratified,
+	}
+	for name, sampler := range samplers {
+		indices, err := sampler.Indices(len(targets), rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorted := append([]int(nil), indices...)
+		sort.Ints(sorted)
+		for i, index := range sorted {
+			if index != i {
+				t.Fatalf("%s: got indices %v, want a permutation of %d rows", name, indices, len(targets))
+			}
+		}
+	}
+}
+
+func TestStratifiedSamplerSpreadsClasses(t *testing.T) {
+	targets := make([][]float64, 40)
+	for i := range targets {
+		if i >= 30 {
+			targets[i] = []float64{1}
+		} else {
+			targets[i] = []float64{0}
+		}
+	}
+	sampler, err := NewStratifiedSampler(targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indices, err := sampler.Indices(len(targets), rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every quarter of the epoch holds about a quarter of the minority class
+	for quarter := 0; quarter < 4; quarter++ {
+		minority := 0
+		for _, index := range indices[quarter*10 : (quarter+1)*10] {
+			if targets[index][0] == 1 {
+				minority = minority + 1
+			}
+		}
+		if minority < 1 || minority > 4 {
+			t.Errorf("quarter %d: got %d rows of the minority class, want about 2.5", quarter, minority)
+		}
+	}
+	if _, err = sampler.Indices(len(targets)-1, rand.New(rand.NewSource(2))); err != ErrSamplerRows {
+		t.Errorf("got error %v, want %v", err, ErrSamplerRows)
+	}
+}
+
+func TestWeightedSamplerFollowsWeights(t *testing.T) {
+	sampler, err := NewWeightedSampler([]float64{0, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, 3)
+	random := rand.New(rand.NewSource(3))
+	for i := 0; i < 2000; i++ {
+		indices, err := sampler.Indices(3, random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range indices {
+			counts[index] = counts[index] + 1
+		}
+	}
+	if counts[0] != 0 {
+		t.Errorf("got %d draws of a row with zero weight", counts[0])
+	}
+	if ratio := float64(counts[2]) / float64(counts[1]); ratio < 2.7 || ratio > 3.3 {
+		t.Errorf("got draw ratio %v, want about 3", ratio)
+	}
+
+	for _, weights := range [][]float64{{}, {0, 0}, {1, -1}} {
+		if _, err = NewWeightedSampler(weights); err != ErrSamplerWeights {
+			t.Errorf("%v: got error %v, want %v", weights, err, ErrSamplerWeights)
+		}
+	}
+}
+
+func TestBatchesKeepTheLastPartialBatch(t *testing.T) {
+	batches, err := Batches([]int{4, 3, 2, 1, 0}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 || batches[2][0] != 0 {
+		t.Errorf("got batches %v", batches)
+	}
+	if _, err = Batches([]int{0}, 0); err != ErrBatchSize {
+		t.Errorf("got error %v, want %v", err, ErrBatchSize)
+	}
+}
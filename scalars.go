@@ -4,6 +4,12 @@ import "math"
 
 var precisionFactor = 0.65
 
+// Kinds of preprocessing that can be attached to a model
+const (
+	StandardScalarPreprocessing = "standard_scalar"
+	NormalizerPreprocessing     = "normalizer"
+)
+
 // NewStandardScalar return a new StandarScalar pointer
 func NewStandardScalar(columns int) *StandardScalar {
 	return &StandardScalar{
@@ -103,3 +109,77 @@ func (n *Normalizer) Transform(data [][]float64, high, low float64) [][]float64
 	}
 	return scaled
 }
+
+// SetStandardScalar attaches a fitted StandardScalar to the model so that it is saved with it and used by Preprocess
+func (nn *network) SetStandardScalar(ss *StandardScalar) error {
+	return nn.setPreprocessing(&attachedPreprocessing{
+		Kind: StandardScalarPreprocessing,
+		Mean: ss.mean,
+		Dev:  ss.dev,
+	})
+}
+
+// SetNormalizer attaches a fitted Normalizer and its range to the model so that it is saved with it and used by Preprocess
+func (nn *network) SetNormalizer(n *Normalizer, high, low float64) error {
+	return nn.setPreprocessing(&attachedPreprocessing{
+		Kind: NormalizerPreprocessing,
+		Min:  n.min,
+		Max:  n.max,
+		High: high,
+		Low:  low,
+	})
+}
+
+// setPreprocessing attaches a copy of the preprocessing to the model after checking it matches the input layer
+func (nn *network) setPreprocessing(p *attachedPreprocessing) error {
+	var columns []int
+	switch p.Kind {
+	case StandardScalarPreprocessing:
+		columns = []int{len(p.Mean), len(p.Dev)}
+	case NormalizerPreprocessing:
+		columns = []int{len(p.Min), len(p.Max)}
+	default:
+		return ErrUnknownPreprocessing
+	}
+	for _, column := range columns {
+		if column != nn.inputNodes() {
+			return ErrRowColumnDimension
+		}
+	}
+
+	nn.preprocessing = &attachedPreprocessing{
+		Kind: p.Kind,
+		Mean: append([]float64(nil), p.Mean...),
+		Dev:  append([]float64(nil), p.Dev...),
+		Min:  append([]float64(nil), p.Min...),
+		Max:  append([]float64(nil), p.Max...),
+		High: p.High,
+		Low:  p.Low,
+	}
+	return nil
+}
+
+// Preprocessing returns the kind of preprocessing attached to the model or an empty string
+func (nn *network) Preprocessing() string {
+	if nn.preprocessing == nil {
+		return ""
+	}
+	return nn.preprocessing.Kind
+}
+
+// Preprocess transforms raw input rows with the preprocessing attached to the model
+// Rows are returned unchanged when no preprocessing is attached
+func (nn *network) Preprocess(data [][]float64) [][]float64 {
+	if nn.preprocessing == nil {
+		return data
+	}
+
+	switch nn.preprocessing.Kind {
+	case StandardScalarPreprocessing:
+		ss := &StandardScalar{nn.preprocessing.Mean, nn.preprocessing.Dev}
+		return ss.Transform(data)
+	default:
+		n := &Normalizer{nn.preprocessing.Max, nn.preprocessing.Min}
+		return n.Transform(data, nn.preprocessing.High, nn.preprocessing.Low)
+	}
+}
@@ -0,0 +1,412 @@
+package gomlp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Names of the file formats of test vectors
+const (
+	ReadMemHVectors = "readmemh"
+	ReadMemBVectors = "readmemb"
+	CSVVectors      = "csv"
+)
+
+// Names of the number formats test vectors are generated in
+const (
+	FloatVectors      = "float64"
+	FixedPointVectors = "fixed_point"
+	RNSVectors        = "rns"
+)
+
+// Encodings of the words of test vectors
+const (
+	twosComplementEncoding = "two's complement"
+	unsignedEncoding       = "unsigned"
+	binary64Encoding       = "IEEE 754 binary64"
+)
+
+var testVectorManifest = "test_vectors.json"
+
+// vectorLayout describes how the values of a test vector file are laid out
+var vectorLayout = "one line per row, address = row * values + value"
+
+// classWidth is the width of the two's complement words holding predicted class indices
+var classWidth = 32
+
+// vectorManifest is the Data Structure to hold the description of written test vectors
+type vectorManifest struct {
+	Mode     string               `json:"mode"`
+	Format   string               `json:"format"`
+	Radix    int                  `json:"radix"`
+	Rows     int                  `json:"rows"`
+	Overflow string               `json:"overflow,omitempty"`
+	Rounding string               `json:"rounding,omitempty"`
+	Moduli   []int64              `json:"moduli,omitempty"`
+	Classes  []float64            `json:"classes,omitempty"`
+	Layout   string               `json:"layout"`
+	Files    []vectorManifestFile `json:"files"`
+}
+
+// vectorManifestFile is the Data Structure to hold the description of a single test vector file
+type vectorManifestFile struct {
+	File        string             `json:"file"`
+	Description string             `json:"description"`
+	Values      int                `json:"values"`
+	Width       int                `json:"width"`
+	Encoding    string             `json:"encoding"`
+	Format      *hdlManifestFormat `json:"format,omitempty"`
+}
+
+// vectorFile is the Data Structure to hold the words of a single test vector file
+type vectorFile struct {
+	name        string
+	description string
+	width       int
+	encoding    string
+	format      *hdlManifestFormat
+	rows        [][]uint64
+}
+
+// HexVectorFormat returns the format writing hexadecimal words for the Verilog $readmemh task
+func HexVectorFormat() VectorFormat {
+	return VectorFormat{ReadMemHVectors, 16}
+}
+
+// BinaryVectorFormat returns the format writing binary words for the Verilog $readmemb task
+func BinaryVectorFormat() VectorFormat {
+	return VectorFormat{ReadMemBVectors, 2}
+}
+
+// CSVVectorFormat returns the format writing comma separated words in a radix of 2, 8, 10 or 16
+// Words in radix 10 are signed integers for two's complement values and real numbers for floating point values
+func CSVVectorFormat(radix int) VectorFormat {
+	return VectorFormat{CSVVectors, radix}
+}
+
+// Name returns the name of the file format
+func (f VectorFormat) Name() string {
+	return f.name
+}
+
+// Radix returns the radix of the written words
+func (f VectorFormat) Radix() int {
+	return f.radix
+}
+
+// extension returns the file extension of the format
+func (f VectorFormat) extension() (string, error) {
+	switch f.name {
+	case ReadMemHVectors:
+		return memoryExtensions[ReadMemH], nil
+	case ReadMemBVectors:
+		return ".mem", nil
+	case CSVVectors:
+		if f.radix == 2 || f.radix == 8 || f.radix == 10 || f.radix == 16 {
+			return ".csv", nil
+		}
+	}
+	return "", ErrVectorFormat
+}
+
+// word returns a word as text in the radix of the format padded to the width
+func (f VectorFormat) word(word uint64, width int, encoding string) string {
+	if f.radix == 10 {
+		switch encoding {
+		case binary64Encoding:
+			return strconv.FormatFloat(math.Float64frombits(word), 'g', -1, 64)
+		case twosComplementEncoding:
+			shift := uint(64 - width)
+			return strconv.FormatInt(int64(word<<shift)>>shift, 10)
+		default:
+			return strconv.FormatUint(word, 10)
+		}
+	}
+
+	digits := (width + bits.Len(uint(f.radix)) - 2) / (bits.Len(uint(f.radix)) - 1)
+	text := strconv.FormatUint(word, f.radix)
+	return strings.Repeat("0", digits-len(text)) + text
+}
+
+// WriteTestVectors runs the classifier over a dataset and writes the inputs, the weighted sums and outputs of every
+// layer, the final outputs and the predicted classes as test vector files with a manifest
+// Every value is the bit pattern of a float64
+func (mlp *Classifier) WriteTestVectors(path string, format VectorFormat, data [][]float64) error {
+	if len(data) == 0 {
+		return ErrRowColumnDimension
+	}
+	last := len(mlp.layers) - 1
+	files := []vectorFile{{"inputs", "inputs", 64, binary64Encoding, nil, nil}}
+	for i := range mlp.layers {
+		files = append(files, vectorFile{fmt.Sprintf("layer_%d_sums", i), fmt.Sprintf("weighted sums of layer %d", i), 64, binary64Encoding, nil, nil})
+		if i < last {
+			files = append(files, vectorFile{fmt.Sprintf("layer_%d_outputs", i), fmt.Sprintf("outputs of layer %d", i), 64, binary64Encoding, nil, nil})
+		}
+	}
+	files = append(files, vectorFile{"outputs", "outputs of the network", 64, binary64Encoding, nil, nil})
+	files = append(files, classVectorFile())
+
+	for _, row := range data {
+		inputs, err := ConvertFromArrayToMatrix1D(row)
+		if err != nil {
+			return err
+		}
+		zs, activations, err := mlp.feedForward(inputs)
+		if err != nil {
+			return err
+		}
+
+		values := [][]float64{activations[0].ConvertFromMatrixToArray1D()}
+		for i, z := range zs {
+			values = append(values, z.ConvertFromMatrixToArray1D())
+			if i < last {
+				values = append(values, activations[i+1].ConvertFromMatrixToArray1D())
+			}
+		}
+		outputs := activations[last+1].ConvertFromMatrixToArray1D()
+		values = append(values, outputs)
+		for i, vector := range values {
+			words := make([]uint64, len(vector))
+			for j, x := range vector {
+				words[j] = math.Float64bits(x)
+			}
+			files[i].rows = append(files[i].rows, words)
+		}
+		files[len(files)-1].rows = append(files[len(files)-1].rows, classWords(classifyOutputs(outputs)))
+	}
+
+	manifest := vectorManifest{
+		Mode:    FloatVectors,
+		Classes: mlp.Classes,
+	}
+	return writeVectors(path, format, manifest, files)
+}
+
+// WriteTestVectors runs the fixed point classifier over a dataset and writes the quantized inputs, the accumulators
+// and outputs of every layer, the final outputs and the predicted classes as test vector files with a manifest
+// Every value is the bit pattern Forward computes
+func (fc *FixedPointClassifier) WriteTestVectors(path string, format VectorFormat, data [][]float64) error {
+	if len(data) == 0 {
+		return ErrRowColumnDimension
+	}
+	config := fc.config
+	activations := config.activations.manifest()
+	accumulator := config.accumulator.manifest()
+	last := len(fc.layers) - 1
+	files := []vectorFile{{"inputs", "quantized inputs", activations.Width, twosComplementEncoding, &activations, nil}}
+	for i := range fc.layers {
+		files = append(files, vectorFile{fmt.Sprintf("layer_%d_sums", i), fmt.Sprintf("accumulators of layer %d", i), accumulator.Width, twosComplementEncoding, &accumulator, nil})
+		if i < last {
+			files = append(files, vectorFile{fmt.Sprintf("layer_%d_outputs", i), fmt.Sprintf("outputs of layer %d", i), activations.Width, twosComplementEncoding, &activations, nil})
+		}
+	}
+	files = append(files, vectorFile{"outputs", "outputs of the network", activations.Width, twosComplementEncoding, &activations, nil})
+	files = append(files, classVectorFile())
+
+	for _, row := range data {
+		sums, outputs, err := fc.ForwardLayers(row)
+		if err != nil {
+			return err
+		}
+
+		values := [][]int64{outputs[0]}
+		for i, sum := range sums {
+			values = append(values, sum)
+			if i < last {
+				values = append(values, outputs[i+1])
+			}
+		}
+		values = append(values, outputs[last+1])
+		for i, vector := range values {
+			files[i].rows = append(files[i].rows, fixedPointWords(vector, files[i].width))
+		}
+		files[len(files)-1].rows = append(files[len(files)-1].rows, classWords(fc.classify(outputs[last+1])))
+	}
+
+	manifest := vectorManifest{
+		Mode:     FixedPointVectors,
+		Overflow: config.overflow.String(),
+		Rounding: config.rounding.String(),
+		Classes:  fc.Classes,
+	}
+	return writeVectors(path, format, manifest, files)
+}
+
+// WriteTestVectors runs the residue number system classifier over a dataset and writes the quantized inputs, the
+// residues of the sums and the outputs of every layer, the final outputs and the predicted classes as test vector
+// files with a manifest
+// The residues of every sum follow each other in the order of the moduli with words as wide as the largest modulus
+func (rc *RNSClassifier) WriteTestVectors(path string, format VectorFormat, data [][]float64) error {
+	if len(data) == 0 {
+		return ErrRowColumnDimension
+	}
+	config := rc.config
+	activations := config.activations.manifest()
+	moduli := rc.system.Moduli()
+	var largest int64
+	for _, m := range moduli {
+		if m > largest {
+			largest = m
+		}
+	}
+	residueWidth := bits.Len64(uint64(largest - 1))
+
+	last := len(rc.layers) - 1
+	files := []vectorFile{{"inputs", "quantized inputs", activations.Width, twosComplementEncoding, &activations, nil}}
+	for i := range rc.layers {
+		files = append(files, vectorFile{fmt.Sprintf("layer_%d_sums", i), fmt.Sprintf("residues of the sums of layer %d", i), residueWidth, unsignedEncoding, nil, nil})
+		if i < last {
+			files = append(files, vectorFile{fmt.Sprintf("layer_%d_outputs", i), fmt.Sprintf("outputs of layer %d", i), activations.Width, twosComplementEncoding, &activations, nil})
+		}
+	}
+	files = append(files, vectorFile{"outputs", "outputs of the network", activations.Width, twosComplementEncoding, &activations, nil})
+	files = append(files, classVectorFile())
+
+	for _, row := range data {
+		sums, outputs, err := rc.ForwardLayers(row)
+		if err != nil {
+			return err
+		}
+
+		files[0].rows = append(files[0].rows, fixedPointWords(outputs[0], activations.Width))
+		next := 1
+		for i, sum := range sums {
+			var words []uint64
+			for _, residues := range sum {
+				for _, residue := range residues {
+					words = append(words, uint64(residue))
+				}
+			}
+			files[next].rows = append(files[next].rows, words)
+			next++
+			if i < last {
+				files[next].rows = append(files[next].rows, fixedPointWords(outputs[i+1], activations.Width))
+				next++
+			}
+		}
+		files[next].rows = append(files[next].rows, fixedPointWords(outputs[last+1], activations.Width))
+		files[next+1].rows = append(files[next+1].rows, classWords(rc.classify(outputs[last+1])))
+	}
+
+	manifest := vectorManifest{
+		Mode:     RNSVectors,
+		Overflow: config.overflow.String(),
+		Rounding: config.rounding.String(),
+		Moduli:   moduli,
+		Classes:  rc.Classes,
+	}
+	return writeVectors(path, format, manifest, files)
+}
+
+// classify returns the class index of raw activation format outputs in the same way as Classifier.Predict
+func (fp *fixedPointNetwork) classify(raw []int64) int {
+	return classifyRaw(raw, fp.config.activations)
+}
+
+// classify returns the class index of raw activation format outputs in the same way as Classifier.Predict
+func (rn *rnsNetwork) classify(raw []int64) int {
+	return classifyRaw(raw, rn.config.activations)
+}
+
+// classifyRaw returns the class index of raw outputs of a format
+func classifyRaw(raw []int64, format QFormat) int {
+	outputs := make([]float64, len(raw))
+	for i, value := range raw {
+		outputs[i] = format.ToFloat(value)
+	}
+	return classifyOutputs(outputs)
+}
+
+// classVectorFile returns the empty test vector file of the predicted classes
+func classVectorFile() vectorFile {
+	return vectorFile{"classes", "predicted class indices", classWidth, twosComplementEncoding, nil, nil}
+}
+
+// classWords returns the word of a predicted class index
+func classWords(class int) []uint64 {
+	return []uint64{uint64(class) & (uint64(1)<<uint(classWidth) - 1)}
+}
+
+// fixedPointWords returns the two's complement words of raw values
+func fixedPointWords(values []int64, width int) []uint64 {
+	words := make([]uint64, len(values))
+	for i, value := range values {
+		words[i] = uint64(value) & (uint64(1)<<uint(width) - 1)
+	}
+	return words
+}
+
+// writeVectors writes every test vector file and the manifest into a directory creating it if needed
+func writeVectors(path string, format VectorFormat, manifest vectorManifest, files []vectorFile) error {
+	extension, err := format.extension()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	manifest.Format = format.name
+	manifest.Radix = format.radix
+	manifest.Rows = len(files[0].rows)
+	manifest.Layout = vectorLayout
+	for _, vectors := range files {
+		described := vectorManifestFile{
+			File:        vectors.name + extension,
+			Description: vectors.description,
+			Width:       vectors.width,
+			Encoding:    vectors.encoding,
+			Format:      vectors.format,
+		}
+		if len(vectors.rows) > 0 {
+			described.Values = len(vectors.rows[0])
+		}
+		err = writeVectorFile(filepath.Join(path, described.File), format, vectors)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, described)
+	}
+
+	return writeFile(filepath.Join(path, testVectorManifest), func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	})
+}
+
+// writeVectorFile writes a single test vector file
+func writeVectorFile(filename string, format VectorFormat, vectors vectorFile) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return writeVectorRows(w, format, vectors)
+	})
+}
+
+// writeVectorRows writes the rows of a test vector file as $readmemh, $readmemb or CSV lines
+func writeVectorRows(w io.Writer, format VectorFormat, vectors vectorFile) error {
+	writer := bufio.NewWriter(w)
+	separator := " "
+	if format.name == CSVVectors {
+		separator = ","
+	} else {
+		fmt.Fprintf(writer, "// %s: %d rows of %d bit %s words\n", vectors.description, len(vectors.rows), vectors.width, vectors.encoding)
+	}
+
+	for _, row := range vectors.rows {
+		words := make([]string, len(row))
+		for i, word := range row {
+			words[i] = format.word(word, vectors.width, vectors.encoding)
+		}
+		fmt.Fprintf(writer, "%s\n", strings.Join(words, separator))
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,194 @@
+package gomlp
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// readVectors returns the words of every test vector file of a directory by file name without extension
+func readVectors(t *testing.T, dir string) (vectorManifest, map[string][][]uint64) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, testVectorManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest vectorManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := make(map[string][][]uint64)
+	for _, described := range manifest.Files {
+		file, err := os.Open(filepath.Join(dir, described.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows [][]uint64
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "//") {
+				continue
+			}
+			var row []uint64
+			for _, text := range strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == ',' }) {
+				row = append(row, parseWord(t, text, manifest.Radix, described))
+			}
+			if len(row) != described.Values {
+				t.Fatalf("%s: got %d values, want %d", described.File, len(row), described.Values)
+			}
+			rows = append(rows, row)
+		}
+		file.Close()
+		if len(rows) != manifest.Rows {
+			t.Fatalf("%s: got %d rows, want %d", described.File, len(rows), manifest.Rows)
+		}
+		vectors[strings.TrimSuffix(described.File, filepath.Ext(described.File))] = rows
+	}
+	return manifest, vectors
+}
+
+// parseWord returns the word written as text in a radix
+func parseWord(t *testing.T, text string, radix int, described vectorManifestFile) uint64 {
+	t.Helper()
+	if radix != 10 {
+		if want := (described.Width + 3) / 4; radix == 16 && len(text) != want {
+			t.Fatalf("%s: got %q, want %d digits", described.File, text, want)
+		}
+		word, err := strconv.ParseUint(text, radix, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return word
+	}
+	switch described.Encoding {
+	case binary64Encoding:
+		x, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return math.Float64bits(x)
+	case twosComplementEncoding:
+		x, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return uint64(x) & (uint64(1)<<uint(described.Width) - 1)
+	default:
+		word, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return word
+	}
+}
+
+func TestFloatTestVectorsRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 5, 3}, 3)
+	for _, format := range []VectorFormat{HexVectorFormat(), CSVVectorFormat(10)} {
+		dir := t.TempDir()
+		if err := mlp.WriteTestVectors(dir, format, data[:20]); err != nil {
+			t.Fatal(err)
+		}
+		manifest, vectors := readVectors(t, dir)
+		if manifest.Mode != FloatVectors || len(manifest.Files) != 8 {
+			t.Fatalf("got manifest %+v", manifest)
+		}
+		for r, row := range data[:20] {
+			outputs, _ := mlp.PredictProbabilities(row)
+			for j, y := range outputs {
+				if vectors["outputs"][r][j] != math.Float64bits(y) {
+					t.Fatalf("%s row %d: got output %v, want %v", format.Name(), r, math.Float64frombits(vectors["outputs"][r][j]), y)
+				}
+			}
+			if inputs := vectors["inputs"][r]; math.Float64frombits(inputs[0]) != row[0] || math.Float64frombits(inputs[1]) != row[1] {
+				t.Fatalf("%s row %d: got inputs %v", format.Name(), r, inputs)
+			}
+			class, _ := mlp.Predict(row)
+			if vectors["classes"][r][0] != uint64(class) {
+				t.Fatalf("%s row %d: got class %d, want %d", format.Name(), r, vectors["classes"][r][0], class)
+			}
+		}
+	}
+}
+
+func TestFixedPointTestVectorsRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 3}, 3)
+	fc, err := NewFixedPointClassifier(mlp, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []VectorFormat{HexVectorFormat(), BinaryVectorFormat(), CSVVectorFormat(10), CSVVectorFormat(8)} {
+		dir := t.TempDir()
+		if err = fc.WriteTestVectors(dir, format, data[:20]); err != nil {
+			t.Fatal(err)
+		}
+		_, vectors := readVectors(t, dir)
+		for r, row := range data[:20] {
+			sums, outputs, _ := fc.ForwardLayers(row)
+			for name, want := range map[string][]uint64{
+				"inputs":          fixedPointWords(outputs[0], 16),
+				"layer_0_sums":    fixedPointWords(sums[0], 32),
+				"layer_0_outputs": fixedPointWords(outputs[1], 16),
+				"layer_1_sums":    fixedPointWords(sums[1], 32),
+				"outputs":         fixedPointWords(outputs[2], 16),
+			} {
+				for j := range want {
+					if vectors[name][r][j] != want[j] {
+						t.Fatalf("%s %s row %d: got %x, want %x", format.Name(), name, r, vectors[name][r], want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRNSTestVectorsRoundTrip(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 6, 3}, 3)
+	system, _ := NewSpecialRNS(16)
+	rc, err := NewRNSClassifier(mlp, system, testFixedPointConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = rc.WriteTestVectors(dir, HexVectorFormat(), data[:20]); err != nil {
+		t.Fatal(err)
+	}
+	manifest, vectors := readVectors(t, dir)
+	if len(manifest.Moduli) != 3 || manifest.Files[1].Width != 17 {
+		t.Fatalf("got manifest %+v", manifest)
+	}
+	for r, row := range data[:20] {
+		sums, _, _ := rc.ForwardLayers(row)
+		for i, layer := range sums {
+			words := vectors["layer_"+strconv.Itoa(i)+"_sums"][r]
+			for j, residues := range layer {
+				for k, residue := range residues {
+					if words[j*3+k] != uint64(residue) {
+						t.Fatalf("layer %d row %d: got residues %v", i, r, words)
+					}
+				}
+			}
+		}
+		class, _ := rc.Predict(row)
+		if vectors["classes"][r][0] != uint64(class) {
+			t.Fatalf("row %d: got class %d, want %d", r, vectors["classes"][r][0], class)
+		}
+	}
+}
+
+func TestTestVectorsRejectInvalidArguments(t *testing.T) {
+	mlp, data, _ := testClassifier(t, []int{2, 4, 3}, 1)
+	if err := mlp.WriteTestVectors(t.TempDir(), CSVVectorFormat(7), data); err != ErrVectorFormat {
+		t.Errorf("got error %v, want %v", err, ErrVectorFormat)
+	}
+	if err := mlp.WriteTestVectors(t.TempDir(), HexVectorFormat(), nil); err != ErrRowColumnDimension {
+		t.Errorf("got error %v, want %v", err, ErrRowColumnDimension)
+	}
+}
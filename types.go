@@ -1,5 +1,7 @@
 package gomlp
 
+import "math/rand"
+
 // Matrix is the Data Structure for the Matrix Operations
 type Matrix struct {
 	data [][]float64
@@ -9,22 +11,270 @@ type Matrix struct {
 
 // ActivationFunction is the DataStructure to hold the Activation Functions
 type ActivationFunction struct {
-	function  func(float64) float64
-	dfunction func(float64) float64
+	name       string
+	function   func(float64) float64
+	dfunction  func(float64, float64) float64
+	mfunction  func(*Matrix) *Matrix
+	mdfunction func(*Matrix, *Matrix) *Matrix
+}
+
+// LossFunction is the Data Structure to hold the Loss Functions
+type LossFunction struct {
+	name      string
+	function  func(float64, float64) float64
+	dfunction func(float64, float64) float64
+}
+
+// Initializer is the Data Structure to hold a weight initialization scheme
+type Initializer struct {
+	name   string
+	params []float64
+}
+
+// Layer is the Data Structure to hold a fully connected layer of a network
+type Layer struct {
+	inputNodes     int
+	outputNodes    int
+	weights        *Matrix
+	bias           *Matrix
+	activationFunc ActivationFunction
+	weightsInit    Initializer
+	biasInit       Initializer
+}
+
+// network is the Data Structure to hold the stack of layers shared by the models
+type network struct {
+	layerSizes      []int
+	layers          []*Layer
+	optimizer       Optimizer
+	lossFunc        LossFunction
+	batchSize       int
+	sampler         Sampler
+	random          *rand.Rand
+	checkpointDir   string
+	checkpointEvery int
+	preprocessing   *attachedPreprocessing
+	quantization    *FixedPointConfig
+	masks           []*Matrix
+	epochLosses     []float64
 }
 
 // Classifier is the Data Structure to hold an Classifier
 type Classifier struct {
-	inputNodes          int
-	hiddenNodes         int
-	outputNodes         int
-	biasHidden          *Matrix
-	biasOutput          *Matrix
-	weightsInputHidden  *Matrix
-	weightsHiddenOutput *Matrix
-	learningRate        float64
-	activationFunc      ActivationFunction
-	Classes             []float64
+	*network
+	Classes []float64
+}
+
+// Regressor is the Data Structure to hold a Regressor
+type Regressor struct {
+	*network
+}
+
+// RegressionScore is the Data Structure to hold the scores of a Regressor
+type RegressionScore struct {
+	R2   float64
+	RMSE float64
+	MAE  float64
+}
+
+// ApproximationReport is the Data Structure to hold the error of an approximate activation function
+type ApproximationReport struct {
+	Name       string
+	Reference  string
+	Min        float64
+	Max        float64
+	MaxError   float64
+	MaxErrorAt float64
+	MeanError  float64
+}
+
+// QFormat is the Data Structure to hold a signed fixed point format
+type QFormat struct {
+	integerBits  int
+	fractionBits int
+}
+
+// FixedPointConfig is the Data Structure to hold the formats and modes of a fixed point datapath
+type FixedPointConfig struct {
+	weights     QFormat
+	activations QFormat
+	accumulator QFormat
+	overflow    OverflowMode
+	rounding    RoundingMode
+}
+
+// fixedPointLayer is the Data Structure to hold the raw fixed point weights and biases of a layer
+type fixedPointLayer struct {
+	weights        [][]int64
+	bias           []int64
+	activationFunc ActivationFunction
+}
+
+// fixedPointNetwork is the Data Structure to hold a network quantized to fixed point
+type fixedPointNetwork struct {
+	config *FixedPointConfig
+	layers []fixedPointLayer
+}
+
+// FixedPointClassifier is the Data Structure to hold a Classifier quantized to fixed point
+type FixedPointClassifier struct {
+	*fixedPointNetwork
+	Classes []float64
+}
+
+// RNS is the Data Structure to hold a residue number system over pairwise coprime moduli
+type RNS struct {
+	moduli       []int64
+	dynamicRange int64
+	inverses     [][]int64
+	crt          []int64
+}
+
+// rnsLayer is the Data Structure to hold the residues of the weights and biases of a layer
+type rnsLayer struct {
+	weights        [][][]int64
+	bias           [][]int64
+	activationFunc ActivationFunction
+}
+
+// rnsNetwork is the Data Structure to hold a network evaluated in a residue number system
+type rnsNetwork struct {
+	system      *RNS
+	config      *FixedPointConfig
+	accumulator QFormat
+	layers      []rnsLayer
+}
+
+// RNSClassifier is the Data Structure to hold a Classifier evaluated in a residue number system
+type RNSClassifier struct {
+	*rnsNetwork
+	Classes []float64
+}
+
+// FaultInjector is the Data Structure to hold the rate and the scope of injected bit flips
+type FaultInjector struct {
+	rate   float64
+	random *rand.Rand
+	layer  int
+	bits   []int
+}
+
+// FaultReport is the Data Structure to hold the results of a fault injection campaign
+type FaultReport struct {
+	Target           FaultTarget
+	Rate             float64
+	Trials           int
+	BaselineAccuracy float64
+	MeanAccuracy     float64
+	MinAccuracy      float64
+	AccuracyDrop     float64
+	MeanFlips        float64
+	Layers           []LayerSensitivity
+}
+
+// LayerSensitivity is the Data Structure to hold the results of faults restricted to a single layer
+type LayerSensitivity struct {
+	Layer        int
+	MeanAccuracy float64
+	AccuracyDrop float64
+	MeanFlips    float64
+}
+
+// TMRClassifier is the Data Structure to hold three copies of a Classifier read through a majority vote
+type TMRClassifier struct {
+	copies    [3]*Classifier
+	voted     *Classifier
+	detected  int
+	corrected int
+}
+
+// RedundantRNSClassifier is the Data Structure to hold a Classifier evaluated in a redundant residue number system
+type RedundantRNSClassifier struct {
+	rns        *RNSClassifier
+	legitimate int64
+	subsystems []*RNS
+	detected   int
+	corrected  int
+}
+
+// ResourceReport is the Data Structure to hold the estimated FPGA resources and latency of a network
+type ResourceReport struct {
+	Arithmetic          string           `json:"arithmetic"`
+	Parallelism         int              `json:"parallelism"`
+	Multipliers         int              `json:"multipliers"`
+	DSPs                int              `json:"dsps"`
+	AdderTreeDepth      int              `json:"adder_tree_depth"`
+	WeightBits          int              `json:"weight_bits"`
+	BRAMBlocks          int              `json:"bram_blocks"`
+	ActivationTableBits int              `json:"activation_table_bits"`
+	LUTs                int              `json:"luts"`
+	Cycles              int              `json:"cycles"`
+	Layers              []LayerResources `json:"layers"`
+}
+
+// LayerResources is the Data Structure to hold the estimated FPGA resources and latency of a layer
+type LayerResources struct {
+	Inputs              int    `json:"inputs"`
+	Outputs             int    `json:"outputs"`
+	Activation          string `json:"activation"`
+	Multipliers         int    `json:"multipliers"`
+	DSPs                int    `json:"dsps"`
+	AdderTreeDepth      int    `json:"adder_tree_depth"`
+	WeightBits          int    `json:"weight_bits"`
+	ActivationTableBits int    `json:"activation_table_bits"`
+	LUTs                int    `json:"luts"`
+	Cycles              int    `json:"cycles"`
+}
+
+// Calibration is the Data Structure to hold the method choosing the range of an int8 activation tensor
+type Calibration struct {
+	name       string
+	percentile float64
+}
+
+// QuantizationParameters is the Data Structure to hold the scale and zero point of an int8 tensor
+type QuantizationParameters struct {
+	Scale     float64
+	ZeroPoint int32
+}
+
+// int8Layer is the Data Structure to hold a layer quantized to int8
+type int8Layer struct {
+	weights        [][]int8
+	bias           []int32
+	weightParams   []QuantizationParameters
+	multipliers    []int32
+	shifts         []int
+	input          QuantizationParameters
+	sum            QuantizationParameters
+	output         QuantizationParameters
+	table          []int8
+	activationFunc ActivationFunction
+}
+
+// int8Network is the Data Structure to hold a network quantized to int8
+type int8Network struct {
+	layers []int8Layer
+}
+
+// Int8Classifier is the Data Structure to hold a Classifier quantized to int8
+type Int8Classifier struct {
+	*int8Network
+	Classes []float64
+}
+
+// QuantizationReport is the Data Structure to hold the accuracy of a quantized model against its float model
+type QuantizationReport struct {
+	FloatAccuracy     float64
+	QuantizedAccuracy float64
+	AccuracyDrop      float64
+	Agreement         float64
+}
+
+// VectorFormat is the Data Structure to hold the file format and radix of test vectors
+type VectorFormat struct {
+	name  string
+	radix int
 }
 
 // StandardScalar is the Data Structure to hold the Standard Scalar Object